@@ -0,0 +1,254 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"debug/elf"
+	"io"
+	"sort"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// An AddressRange is the range of addresses [Start, End).
+type AddressRange struct {
+	Start, End uint64
+}
+
+// Contains reports whether addr lies within the range.
+func (r AddressRange) Contains(addr uint64) bool {
+	return addr >= r.Start && addr < r.End
+}
+
+// Intersects reports whether the ranges share at least one address.
+func (r AddressRange) Intersects(other AddressRange) bool {
+	return r.Start < other.End && other.Start < r.End
+}
+
+// Union returns the smallest range containing both ranges, including any gap
+// between them.
+func (r AddressRange) Union(other AddressRange) AddressRange {
+	if other.Start < r.Start {
+		r.Start = other.Start
+	}
+	if other.End > r.End {
+		r.End = other.End
+	}
+	return r
+}
+
+// sectionAddressRange implements Section.AddressRange.
+func sectionAddressRange(s Section) AddressRange {
+	return AddressRange{s.Address(), s.Address() + s.Size()}
+}
+
+// isAllocated checks if a section occupies target memory.
+func isAllocated(s Section) bool {
+	if s.Size() == 0 {
+		return false
+	}
+	switch s := s.(type) {
+	case *elfSection:
+		return s.s.Flags&elf.SHF_ALLOC != 0
+	case *coffSection:
+		return s.s.Flags&(coff.STYP_DSECT|coff.STYP_COPY|coff.STYP_PAD) == 0
+	}
+	return false
+}
+
+// MinAddress returns the lowest address of any allocated section, ok is false
+// if there are no allocated sections.
+func (f *File) MinAddress() (addr uint64, ok bool) {
+	for _, s := range f.Sections {
+		if isAllocated(s) && (!ok || s.Address() < addr) {
+			addr, ok = s.Address(), true
+		}
+	}
+	return
+}
+
+// MaxAddress returns the highest address, inclusive, of any allocated section,
+// ok is false if there are no allocated sections.
+func (f *File) MaxAddress() (addr uint64, ok bool) {
+	for _, s := range f.Sections {
+		if !isAllocated(s) {
+			continue
+		}
+		if last := s.Address() + s.Size() - 1; !ok || last > addr {
+			addr, ok = last, true
+		}
+	}
+	return
+}
+
+// SegmentBoundaries returns the start addresses of the allocated sections,
+// sorted and without duplicates. Sections which are not allocated are left
+// out, their addresses are not meaningful.
+func (f *File) SegmentBoundaries() []uint64 {
+	var addrs []uint64
+	for _, s := range f.Sections {
+		if isAllocated(s) {
+			addrs = append(addrs, s.Address())
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return addrs[i] < addrs[j]
+	})
+
+	boundaries := addrs[:0]
+	for i, addr := range addrs {
+		if i == 0 || addr != addrs[i-1] {
+			boundaries = append(boundaries, addr)
+		}
+	}
+	return boundaries
+}
+
+// hasFileData checks if a section has contents stored in the file.
+func hasFileData(s Section) bool {
+	switch s := s.(type) {
+	case *elfSection:
+		return s.s.Type != elf.SHT_NOBITS
+	case *coffSection:
+		return s.s.RawDataAddress != 0 && s.s.Flags&coff.STYP_BSS == 0
+	}
+	return false
+}
+
+// SectionContaining returns the allocated section whose address range
+// includes addr.
+func (f *File) SectionContaining(addr uint64) (Section, bool) {
+	for _, s := range f.Sections {
+		if isAllocated(s) && s.AddressRange().Contains(addr) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// ContainsAddress reports whether any allocated section with file data
+// includes addr. Sections without file data, such as .bss, are ignored.
+//
+// The sections are indexed by address on the first call, so lookups take
+// logarithmic time.
+func (f *File) ContainsAddress(addr uint64) bool {
+	f.addrIndexOnce.Do(f.buildAddrIndex)
+
+	i := sort.Search(len(f.addrIndex), func(i int) bool {
+		return f.addrIndex[i].Start > addr
+	})
+	return i > 0 && f.addrIndex[i-1].Contains(addr)
+}
+
+// buildAddrIndex builds the address index, the address ranges of the
+// sections sorted by start address. The end of each range is extended to the
+// highest end of the preceding ranges, which accounts for overlapping
+// sections.
+func (f *File) buildAddrIndex() {
+	for _, s := range f.Sections {
+		if isAllocated(s) && hasFileData(s) {
+			f.addrIndex = append(f.addrIndex, s.AddressRange())
+		}
+	}
+	sort.Slice(f.addrIndex, func(i, j int) bool {
+		return f.addrIndex[i].Start < f.addrIndex[j].Start
+	})
+	for i := 1; i < len(f.addrIndex); i++ {
+		if prev := f.addrIndex[i-1].End; prev > f.addrIndex[i].End {
+			f.addrIndex[i].End = prev
+		}
+	}
+}
+
+// imageSectionContaining is like SectionContaining, but only considers
+// sections with file data. If there is none, it returns the address of the
+// next such section above addr, or ok false if there is none.
+func (f *File) imageSectionContaining(addr uint64) (section Section, next uint64, ok bool) {
+	for _, s := range f.Sections {
+		if !isAllocated(s) || !hasFileData(s) {
+			continue
+		}
+		if s.AddressRange().Contains(addr) {
+			return s, 0, true
+		}
+		if s.Address() > addr && (!ok || s.Address() < next) {
+			next, ok = s.Address(), true
+		}
+	}
+	return nil, next, ok
+}
+
+// ReadAt reads from the memory image of the file, where off is a virtual
+// address. Gaps between sections read as 0xFF, as erased flash does, and
+// io.EOF is returned once the read passes the end of the last section.
+// Sections without file data, such as .bss, are treated as gaps, also when
+// they come last.
+func (f *File) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, io.EOF
+	}
+
+	max, hasMax := f.MaxAddress()
+	addr := uint64(off)
+	for n < len(p) {
+		s, next, ok := f.imageSectionContaining(addr)
+		if !ok && hasMax && addr <= max {
+			// Past the last section with file data, but not past the
+			// sections without it
+			next, ok = max+1, true
+		}
+		switch {
+		case s != nil:
+			sectionOff := addr - s.Address()
+			chunk := p[n:]
+			if remaining := s.Size() - sectionOff; uint64(len(chunk)) > remaining {
+				chunk = chunk[:remaining]
+			}
+			var m int
+			m, err = s.ReadAt(chunk, int64(sectionOff))
+			n += m
+			addr += uint64(m)
+			if err != nil && m < len(chunk) {
+				return
+			}
+			err = nil
+
+		case ok:
+			gap := p[n:]
+			if uint64(len(gap)) > next-addr {
+				gap = gap[:next-addr]
+			}
+			for i := range gap {
+				gap[i] = 0xFF
+			}
+			n += len(gap)
+			addr += uint64(len(gap))
+
+		default:
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// ReadMemory returns size bytes of the memory image of the file starting at
+// the virtual address addr, read as by ReadAt. If the range extends past the
+// end of the last section, the bytes up to the end are returned with io.EOF.
+func (f *File) ReadMemory(addr, size uint64) ([]byte, error) {
+	max, ok := f.MaxAddress()
+	if !ok || addr > max {
+		return nil, io.EOF
+	}
+	truncated := size > max-addr+1
+	if truncated {
+		size = max - addr + 1
+	}
+
+	b := make([]byte, size)
+	n, err := f.ReadAt(b, int64(addr))
+	if err == nil && truncated {
+		err = io.EOF
+	}
+	return b[:n], err
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ParseBytes creates a new File backed by b. Modifications made through the
+// file, such as Section.Fill, are written directly to b.
+func ParseBytes(b []byte, opts ...Option) (*File, error) {
+	return NewFile(&byteBuffer{b}, opts...)
+}
+
+// byteBuffer is a fixed size in-memory file.
+type byteBuffer struct {
+	b []byte
+}
+
+func (buf *byteBuffer) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(len(buf.b)) {
+		return 0, io.EOF
+	}
+	n = copy(p, buf.b[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+func (buf *byteBuffer) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(p)) > int64(len(buf.b)) {
+		return 0, errors.New("write out of range")
+	}
+	return copy(buf.b[off:], p), nil
+}
+
+// writerAt returns r if it is writable memory, otherwise nil.
+func writerAt(r io.ReaderAt) io.WriterAt {
+	if buf, ok := r.(*byteBuffer); ok {
+		return buf
+	}
+	return nil
+}
+
+// fill writes n copies of value at off.
+func fill(w io.WriterAt, value byte, off int64, n uint64) error {
+	_, err := w.WriteAt(bytes.Repeat([]byte{value}, int(n)), off)
+	return err
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+// Package ar implements access to archives of TI-COFF object files, as
+// produced by the TI librarian.
+package ar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+const (
+	magic      = "!<arch>\n"
+	headerSize = 60
+)
+
+var ErrInvalidMagic = errors.New("invalid archive magic")
+
+// An Archive represents an open archive of COFF files.
+type Archive struct {
+	members []*ArchiveMember
+
+	// index maps symbol names to the member defining them.
+	index map[string]*ArchiveMember
+
+	closer io.Closer
+}
+
+// An ArchiveMember is an object file contained in an archive.
+type ArchiveMember struct {
+	*coff.File
+
+	// Name is the file name of the member.
+	Name string
+
+	// Offset is the file offset of the member header within the archive.
+	Offset int64
+
+	// Size is the size in bytes of the member data.
+	Size int64
+}
+
+// header is an archive member header.
+type header struct {
+	Name  [16]byte
+	Date  [12]byte
+	UID   [6]byte
+	GID   [6]byte
+	Mode  [8]byte
+	Size  [10]byte
+	Magic [2]byte
+}
+
+// NewArchive creates a new Archive for accessing an archive in an underlying
+// reader.
+func NewArchive(r io.ReaderAt) (*Archive, error) {
+	var buf [len(magic)]byte
+	if _, err := r.ReadAt(buf[:], 0); err != nil {
+		return nil, err
+	}
+	if string(buf[:]) != magic {
+		return nil, ErrInvalidMagic
+	}
+
+	a := &Archive{
+		index: make(map[string]*ArchiveMember),
+	}
+
+	var (
+		offset      = int64(len(magic))
+		symbolTable []byte
+		longNames   []byte
+	)
+	for {
+		var h header
+		err := binary.Read(io.NewSectionReader(r, offset, headerSize), binary.LittleEndian, &h)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if string(h.Magic[:]) != "`\n" {
+			return nil, fmt.Errorf("member at offset %d: invalid header", offset)
+		}
+
+		size, err := strconv.ParseInt(strings.TrimSpace(string(h.Size[:])), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("member at offset %d: invalid size: %v", offset, err)
+		}
+		if size < 0 || size > 1<<62 {
+			return nil, fmt.Errorf("member at offset %d: invalid size %d", offset, size)
+		}
+		data := io.NewSectionReader(r, offset+headerSize, size)
+
+		// Check that the member data is present before allocating for it
+		if size > 0 {
+			var b [1]byte
+			if _, err = data.ReadAt(b[:], size-1); err != nil {
+				return nil, fmt.Errorf("member at offset %d: size %d exceeds archive", offset, size)
+			}
+		}
+
+		name := strings.TrimRight(string(h.Name[:]), " ")
+		switch {
+		case name == "/":
+			// Symbol table
+			symbolTable = make([]byte, size)
+			if _, err = data.ReadAt(symbolTable, 0); err != nil {
+				return nil, err
+			}
+
+		case name == "//":
+			// Long member name table
+			longNames = make([]byte, size)
+			if _, err = data.ReadAt(longNames, 0); err != nil {
+				return nil, err
+			}
+
+		default:
+			name, err = memberName(name, longNames)
+			if err != nil {
+				return nil, fmt.Errorf("member at offset %d: %v", offset, err)
+			}
+
+			m := &ArchiveMember{
+				Name:   name,
+				Offset: offset,
+				Size:   size,
+			}
+			m.File, err = coff.NewFile(data)
+			if err != nil {
+				return nil, fmt.Errorf("member %s: %v", name, err)
+			}
+			a.members = append(a.members, m)
+		}
+
+		// Member data is aligned to an even offset
+		next := offset + headerSize + size + size%2
+		if next <= offset {
+			return nil, fmt.Errorf("member at offset %d: invalid size %d", offset, size)
+		}
+		offset = next
+	}
+
+	if symbolTable != nil {
+		if err := a.readIndex(symbolTable); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// memberName resolves a member name, looking up names of the form "/n" in the
+// long name table.
+func memberName(name string, longNames []byte) (string, error) {
+	if !strings.HasPrefix(name, "/") {
+		return strings.TrimSuffix(name, "/"), nil
+	}
+
+	off, err := strconv.Atoi(name[1:])
+	if err != nil || off < 0 || off >= len(longNames) {
+		return "", fmt.Errorf("invalid long name reference %q", name)
+	}
+	s := longNames[off:]
+	if i := bytes.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(string(s), "/"), nil
+}
+
+// readIndex reads the archive symbol table, which holds a big-endian symbol
+// count, that many big-endian member header offsets and then the
+// null-terminated symbol names.
+func (a *Archive) readIndex(symbolTable []byte) error {
+	if len(symbolTable) < 4 {
+		return errors.New("symbol table truncated")
+	}
+	n := int(binary.BigEndian.Uint32(symbolTable))
+	if n < 0 || len(symbolTable) < 4+4*n {
+		return errors.New("symbol table truncated")
+	}
+
+	byOffset := make(map[int64]*ArchiveMember, len(a.members))
+	for _, m := range a.members {
+		byOffset[m.Offset] = m
+	}
+
+	names := symbolTable[4+4*n:]
+	for i := 0; i < n; i++ {
+		end := bytes.IndexByte(names, 0)
+		if end < 0 {
+			return errors.New("symbol table truncated")
+		}
+		name := string(names[:end])
+		names = names[end+1:]
+
+		offset := int64(binary.BigEndian.Uint32(symbolTable[4+4*i:]))
+		m, ok := byOffset[offset]
+		if !ok {
+			return fmt.Errorf("symbol %s: no member at offset %d", name, offset)
+		}
+		if _, exists := a.index[name]; !exists {
+			a.index[name] = m
+		}
+	}
+	return nil
+}
+
+// Open opens the named archive file.
+func Open(name string) (*Archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := NewArchive(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	a.closer = f
+
+	return a, nil
+}
+
+// Close closes the underlying file if there is one.
+func (a *Archive) Close() error {
+	if a.closer != nil {
+		return a.closer.Close()
+	}
+	return nil
+}
+
+// Members returns the object files in the archive, in archive order.
+func (a *Archive) Members() []*ArchiveMember {
+	return a.members
+}
+
+// Lookup returns the member defining the named symbol according to the archive
+// symbol table. If the symbol is defined more than once, the first member is
+// returned.
+func (a *Archive) Lookup(symbol string) (*ArchiveMember, bool) {
+	m, ok := a.index[symbol]
+	return m, ok
+}
+
+// Symbols returns the sorted names of all symbols in the archive symbol table.
+func (a *Archive) Symbols() []string {
+	names := make([]string, 0, len(a.index))
+	for name := range a.index {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
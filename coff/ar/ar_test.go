@@ -0,0 +1,23 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package ar
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func memberHeader(name, size string) string {
+	return fmt.Sprintf("%-16s%-12s%-6s%-6s%-8s%-10s`\n", name, "0", "0", "0", "644", size)
+}
+
+func TestNewArchiveInvalidSize(t *testing.T) {
+	for _, size := range []string{"-1", "999999999"} {
+		b := magic + memberHeader("/", size) + "\x00\x00\x00\x00"
+		if _, err := NewArchive(bytes.NewReader([]byte(b))); err == nil {
+			t.Errorf("size %s: expected error", size)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A Builder assembles a COFF file from its parts. The file layout (file
+// offsets, counts and the string table) is computed when the file is written,
+// so those fields of the headers are ignored.
+//
+// Line number entries are not supported, NewBuilder fails with
+// ErrLineNumbers for files that have them.
+type Builder struct {
+	FileHeader FileHeader
+
+	// ByteOrder is the byte order of the file headers, symbol table and
+	// relocation entries. If it is nil, the file is big-endian if FLAG_BIG
+	// is set in FileHeader and little-endian otherwise.
+	ByteOrder binary.ByteOrder
+
+	// OptionalFileHeader is written if it is non-nil.
+	OptionalFileHeader *OptionalFileHeader
+
+	Sections []*BuilderSection
+
+	// Symbols is the symbol table. Relocation entries refer to symbols by
+	// their symbol table index, which must be kept intact when modifying it.
+	Symbols []Symbol
+}
+
+// A BuilderSection is a section to be written by a Builder.
+type BuilderSection struct {
+	SectionHeader
+
+	// Data is the raw data of the section, it is nil for sections without raw
+	// data such as .bss. On C2800 and C5400 its length must be a whole number
+	// of 16-bit words, as the header records the size in words.
+	Data []byte
+
+	RelocationEntries []RelocationEntry
+}
+
+// NewBuilder creates a Builder holding a copy of the contents of f, including
+// the raw data of every section, in the byte order f was parsed with. It
+// returns ErrLineNumbers if any section has line number entries, which the
+// Builder would drop, ErrNoSectionData for files decoded by ReadJSON, and the
+// error of any section whose relocation entries could not be read.
+func NewBuilder(f *File) (*Builder, error) {
+	for _, s := range f.Sections {
+		if s.noData {
+			return nil, fmt.Errorf("section %s: %w", s.Name, ErrNoSectionData)
+		}
+		if s.numLineNumbers > 0 {
+			return nil, fmt.Errorf("section %s: %w", s.Name, ErrLineNumbers)
+		}
+		if s.relocErr != nil {
+			return nil, s.relocErr
+		}
+	}
+
+	b := &Builder{
+		FileHeader: f.FileHeader,
+		ByteOrder:  f.byteOrder,
+		Sections:   make([]*BuilderSection, len(f.Sections)),
+		Symbols:    f.SymbolTable(),
+	}
+	if f.OptionalFileHeader != nil {
+		b.OptionalFileHeader = new(OptionalFileHeader)
+		*b.OptionalFileHeader = *f.OptionalFileHeader
+	}
+
+	for i, s := range f.Sections {
+		bs := &BuilderSection{
+			SectionHeader:     s.SectionHeader,
+			RelocationEntries: append([]RelocationEntry(nil), s.RelocationEntries...),
+		}
+		if s.hasRawData() {
+			bs.Data = make([]byte, s.ByteSize())
+			if _, err := s.sr.ReadAt(bs.Data, 0); err != nil {
+				return nil, err
+			}
+		}
+		b.Sections[i] = bs
+	}
+
+	return b, nil
+}
+
+// byteOrderOf returns the byte order given by the FLAG_BIG file header flag,
+// little-endian if it is not set.
+func byteOrderOf(h *FileHeader) binary.ByteOrder {
+	if h.Flags&FLAG_BIG != 0 {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// hasRawData checks if the section has raw data in the file.
+func (s *Section) hasRawData() bool {
+	return s.RawDataAddress != 0 && s.Size > 0 && !s.noData
+}
+
+// Bytes returns the encoded COFF file.
+func (b *Builder) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := b.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes the encoded COFF file to w.
+func (b *Builder) WriteTo(w io.Writer) (int64, error) {
+	var (
+		buf         bytes.Buffer
+		stringTable = []byte{0, 0, 0, 0}
+		long        = usesLongRelocationEntries(b.FileHeader.TargetID)
+		bo          = b.ByteOrder
+	)
+	if bo == nil {
+		bo = byteOrderOf(&b.FileHeader)
+	}
+
+	name := func(s string) (chars [8]byte) {
+		if len(s) <= len(chars) {
+			copy(chars[:], s)
+			return
+		}
+		bo.PutUint32(chars[4:], uint32(len(stringTable)))
+		stringTable = append(stringTable, s...)
+		stringTable = append(stringTable, 0)
+		return
+	}
+
+	// Lay out the file: headers, raw data, relocation entries, symbol table
+	// and finally the string table.
+	header := b.FileHeader
+	header.NumSections = uint16(len(b.Sections))
+	header.OptionalFileHeaderSize = 0
+	if b.OptionalFileHeader != nil {
+		header.OptionalFileHeaderSize = uint16(binary.Size(b.OptionalFileHeader))
+	}
+
+	offset := uint32(CoffFileHeaderSize) + uint32(header.OptionalFileHeaderSize) +
+		uint32(len(b.Sections))*CoffSectionHeaderSize
+
+	unit := b.FileHeader.TargetID.sizeUnit()
+	headers := make([]sectionHeader, len(b.Sections))
+	for i, s := range b.Sections {
+		headers[i] = sectionHeader{
+			PhysicalAddress:  s.PhysicalAddress,
+			VirtualAddress:   s.VirtualAddress,
+			Size:             s.Size,
+			Flags:            uint32(s.Flags),
+			MemoryPageNumber: s.MemoryPageNumber,
+		}
+		if s.Data != nil {
+			if int64(len(s.Data))%unit != 0 {
+				return 0, fmt.Errorf("section %s: %d bytes of data is not a whole number of %d-byte words", s.Name, len(s.Data), unit)
+			}
+			headers[i].Size = uint32(int64(len(s.Data)) / unit)
+			headers[i].RawDataAddress = offset
+			offset += uint32(len(s.Data))
+		}
+	}
+	relocationEntrySize := uint32(binary.Size(relocationEntry10{}))
+	if long {
+		relocationEntrySize = uint32(binary.Size(relocationEntry12{}))
+	}
+	for i, s := range b.Sections {
+		if len(s.RelocationEntries) > 0 {
+			headers[i].RelocationEntriesAddress = offset
+			headers[i].NumRelocationEntries = uint32(len(s.RelocationEntries))
+			offset += uint32(len(s.RelocationEntries)) * relocationEntrySize
+		}
+	}
+
+	header.SymbolTableStartAddress = offset
+	header.NumSymbolTableEntries = 0
+	for _, sym := range b.Symbols {
+		header.NumSymbolTableEntries++
+		if sym.AuxiliaryEntry != nil {
+			header.NumSymbolTableEntries++
+		}
+	}
+
+	// Encode everything in order
+	binary.Write(&buf, bo, &header)
+	if b.OptionalFileHeader != nil {
+		binary.Write(&buf, bo, b.OptionalFileHeader)
+	}
+	for i, s := range b.Sections {
+		chars := name(s.Name)
+		binary.Write(&buf, bo, &chars)
+		binary.Write(&buf, bo, &headers[i])
+	}
+	for _, s := range b.Sections {
+		buf.Write(s.Data)
+	}
+	for _, s := range b.Sections {
+		for _, r := range s.RelocationEntries {
+			if long {
+				binary.Write(&buf, bo, &relocationEntry12{
+					VirtualAddress:   r.VirtualAddress,
+					SymbolTableIndex: r.SymbolTableIndex,
+					ExtendedAddress:  r.ExtendedAddress,
+					Type:             r.Type,
+				})
+			} else {
+				binary.Write(&buf, bo, &relocationEntry10{
+					VirtualAddress:   r.VirtualAddress,
+					SymbolTableIndex: uint16(r.SymbolTableIndex),
+					Type:             r.Type,
+				})
+			}
+		}
+	}
+	for _, sym := range b.Symbols {
+		chars := name(sym.Name)
+		entry := symbol{
+			Value:         sym.Value,
+			SectionNumber: sym.SectionNumber,
+			Type:          sym.Type,
+			StorageClass:  uint8(sym.StorageClass),
+		}
+		if sym.AuxiliaryEntry != nil {
+			entry.NumAuxEntries = 1
+		}
+		binary.Write(&buf, bo, &chars)
+		binary.Write(&buf, bo, &entry)
+		if sym.AuxiliaryEntry != nil {
+			binary.Write(&buf, bo, sym.AuxiliaryEntry)
+		}
+	}
+	bo.PutUint32(stringTable, uint32(len(stringTable)))
+	buf.Write(stringTable)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
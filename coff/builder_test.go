@@ -0,0 +1,111 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestBuilderBigEndian(t *testing.T) {
+	b := testBuilder()
+	b.FileHeader.Flags = FLAG_BIG | FLAG_EXEC
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.TargetID != 0x00A0 || f.Flags&FLAG_BIG == 0 {
+		t.Errorf("big-endian header read as TargetID %v, Flags %#x", f.TargetID, f.Flags)
+	}
+	if sym, err := f.Symbol("a_long_symbol_name"); err != nil || sym.Value != 0x2000 {
+		t.Errorf("Symbol = %+v, %v", sym, err)
+	}
+	if _, err := NewFileOpts(bytes.NewReader(data), WithByteOrder(binary.LittleEndian)); err != ErrInvalidTargetID {
+		t.Errorf("NewFileOpts with WithByteOrder(binary.LittleEndian) error = %v, want ErrInvalidTargetID", err)
+	}
+
+	var elfData bytes.Buffer
+	if err := f.WriteELF(&elfData); err != nil {
+		t.Fatal(err)
+	}
+	if ef, err := elf.NewFile(bytes.NewReader(elfData.Bytes())); err != nil || ef.Data != elf.ELFDATA2MSB {
+		t.Errorf("WriteELF of a big-endian file: %v", err)
+	}
+
+	nb, err := NewBuilder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := nb.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("big-endian file changed when rebuilt")
+	}
+}
+
+func TestNewBuilderLineNumbers(t *testing.T) {
+	f := testFile(t)
+	f.Sections[0].numLineNumbers = 1
+	if _, err := NewBuilder(f); !errors.Is(err, ErrLineNumbers) {
+		t.Errorf("NewBuilder error = %v, want ErrLineNumbers", err)
+	}
+}
+
+func TestWordAddressedSectionSize(t *testing.T) {
+	b := testBuilder()
+	b.FileHeader.TargetID = 0x009D // C2800 counts section sizes in 16-bit words
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := f.Section(".text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text.Size != 4 || text.ByteSize() != 8 {
+		t.Errorf(".text Size, ByteSize() = %d, %d, want 4, 8", text.Size, text.ByteSize())
+	}
+	want := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	if got, err := f.SectionData(".text"); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("SectionData = % x, %v, want % x", got, err, want)
+	}
+	clone, err := f.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := clone.SectionData(".text"); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("clone SectionData = % x, %v, want % x", got, err, want)
+	}
+	if crc, err := text.CRC16(); err != nil || crc != 0x4792 {
+		t.Errorf("CRC16() = %#04x, %v, want 0x4792", crc, err)
+	}
+
+	nb, err := NewBuilder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := nb.Bytes(); err != nil || !bytes.Equal(out, data) {
+		t.Errorf("rebuilt file differs, err = %v", err)
+	}
+
+	nb.Sections[0].Data = nb.Sections[0].Data[:7]
+	if _, err := nb.Bytes(); err == nil {
+		t.Error("odd number of bytes in a word-addressed section was accepted")
+	}
+}
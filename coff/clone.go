@@ -0,0 +1,53 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"io"
+)
+
+// Clone returns a copy of f which shares no memory with it and does not depend
+// on the underlying reader of f. The section data is read into memory, so the
+// clone remains usable after f is closed. Closing the clone does nothing.
+func (f *File) Clone() (*File, error) {
+	clone := &File{
+		FileHeader:      f.FileHeader,
+		Sections:        make([]*Section, len(f.Sections)),
+		symbols:         f.SymbolTable(),
+		symbolIndex:     append([]int(nil), f.symbolIndex...),
+		rawHeader:       append([]byte(nil), f.rawHeader...),
+		byteOrder:       f.byteOrder,
+		stringTableSize: f.stringTableSize,
+		fileSize:        f.fileSize,
+	}
+	if f.OptionalFileHeader != nil {
+		clone.OptionalFileHeader = new(OptionalFileHeader)
+		*clone.OptionalFileHeader = *f.OptionalFileHeader
+	}
+
+	for i, s := range f.Sections {
+		var data []byte
+		if s.hasRawData() {
+			data = make([]byte, s.ByteSize())
+			if _, err := s.sr.ReadAt(data, 0); err != nil {
+				return nil, err
+			}
+		}
+
+		section := &Section{
+			SectionHeader:     s.SectionHeader,
+			sr:                io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))),
+			rawName:           s.rawName,
+			numLineNumbers:    s.numLineNumbers,
+			RelocationEntries: append([]RelocationEntry(nil), s.RelocationEntries...),
+			relocErr:          s.relocErr,
+			noData:            s.noData,
+		}
+		section.ReaderAt = section.sr
+		clone.Sections[i] = section
+	}
+
+	return clone, nil
+}
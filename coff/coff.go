@@ -7,6 +7,7 @@ package coff
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -14,9 +15,35 @@ import (
 	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
+
+	"github.com/awarepoint/go-debug/internal/mmap"
 )
 
-var ErrInvalidTargetID = errors.New("invalid target ID")
+// Sizes in bytes of the fixed size COFF structures.
+const (
+	CoffFileHeaderSize    = 22
+	CoffSectionHeaderSize = 48
+	CoffSymbolEntrySize   = 18
+	CoffAuxEntrySize      = 18
+)
+
+var (
+	ErrInvalidTargetID           = errors.New("invalid target ID")
+	ErrInvalidSectionNumber      = errors.New("invalid section number")
+	ErrInvalidOptionalMagic      = errors.New("invalid optional file header magic number")
+	ErrInvalidStringOffset       = errors.New("string table offset out of range")
+	ErrTooManySections           = errors.New("too many sections")
+	ErrInvalidSymbolTableAddress = errors.New("invalid symbol table address")
+	ErrSectionOutOfBounds        = errors.New("section data out of bounds")
+	ErrStringTableTooLarge       = errors.New("string table too large")
+	ErrReservedFieldSet          = errors.New("reserved field is not zero")
+	ErrSymbolNotFound            = errors.New("symbol not found")
+	ErrSectionNotFound           = errors.New("section not found")
+	ErrLineNumbers               = errors.New("line number entries are not supported")
+	ErrRelocationsOutOfBounds    = errors.New("relocation entries out of bounds")
+	ErrNoSectionData             = errors.New("section data not available")
+)
 
 // A File represents an open COFF file.
 type File struct {
@@ -29,10 +56,60 @@ type File struct {
 
 	symbols []Symbol
 
+	// symbolIndex maps symbol table indexes to positions in symbols, the
+	// indexes of auxiliary entries map to -1.
+	symbolIndex []int
+
+	rawHeader []byte
+
+	// byteOrder is the byte order the file was parsed with.
+	byteOrder binary.ByteOrder
+
+	sectionIndexOnce sync.Once
+	sectionIndex     map[string]int
+
+	symbolAtOnce sync.Once
+	addrIndex    []addrSymbol
+
+	// valueIndex holds the positions in symbols of the defined symbols,
+	// sorted by value and section number.
+	valueIndexOnce sync.Once
+	valueIndex     []int
+
+	// stringTableSize is the size recorded at the start of the string
+	// table. fileSize is the size of the file if the string table was read
+	// to its end, otherwise zero.
+	stringTableSize uint64
+	fileSize        uint64
+
+	storageClassOnce   sync.Once
+	storageClassGroups map[StorageClass][]Symbol
+
 	closer io.Closer
 }
 
-func NewFile(r io.ReaderAt) (file *File, err error) {
+// NewFile creates a new File for accessing a COFF binary in an underlying
+// reader.
+func NewFile(r io.ReaderAt) (*File, error) {
+	return NewFileOpts(r)
+}
+
+// NewFileCtx is like NewFile, but stops parsing and returns the context error
+// when ctx is done. The context is checked before the section headers and
+// before the symbol table are read.
+func NewFileCtx(ctx context.Context, r io.ReaderAt) (*File, error) {
+	return NewFileOpts(r, withContext(ctx))
+}
+
+// NewFileOpts is like NewFile but configured by opts.
+func NewFileOpts(r io.ReaderAt, opts ...Option) (file *File, err error) {
+	o := options{
+		maxStringTableSize: defaultMaxStringTableSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	file = new(File)
 
 	var (
@@ -43,7 +120,16 @@ func NewFile(r io.ReaderAt) (file *File, err error) {
 	)
 
 	// Read and validate the file header
-	err = binary.Read(sr, binary.LittleEndian, &file.FileHeader)
+	file.rawHeader = make([]byte, binary.Size(file.FileHeader))
+	_, err = io.ReadFull(sr, file.rawHeader)
+	if err != nil {
+		return
+	}
+	if o.byteOrder == nil {
+		o.byteOrder = detectByteOrder(file.rawHeader)
+	}
+	file.byteOrder = o.byteOrder
+	err = binary.Read(bytes.NewReader(file.rawHeader), o.byteOrder, &file.FileHeader)
 	if err != nil {
 		return
 	}
@@ -51,40 +137,72 @@ func NewFile(r io.ReaderAt) (file *File, err error) {
 	if !IsValidTargetID(&file.FileHeader) {
 		return nil, ErrInvalidTargetID
 	}
+	if o.maxSections > 0 && int(file.NumSections) > o.maxSections {
+		return nil, fmt.Errorf("%w: %d sections exceeds limit of %d", ErrTooManySections, file.NumSections, o.maxSections)
+	}
 
 	offset += int64(binary.Size(file.FileHeader))
 
 	if file.OptionalFileHeaderSize > 0 {
 		file.OptionalFileHeader = new(OptionalFileHeader)
-		err = binary.Read(sr, binary.LittleEndian, file.OptionalFileHeader)
+		err = binary.Read(sr, o.byteOrder, file.OptionalFileHeader)
 		if err != nil {
 			return
 		}
 
+		if file.OptionalFileHeader.MagicNumber != OptionalFileHeaderMagicNumber {
+			return nil, ErrInvalidOptionalMagic
+		}
+
 		offset += int64(binary.Size(file.OptionalFileHeader))
 	}
 
-	// Skip ahead to read the string table
-	sr.Seek(int64(file.SymbolTableStartAddress)+(int64(file.NumSymbolTableEntries)*18), 0)
-	stringTable, err := ioutil.ReadAll(sr)
-	if err != nil {
-		return
+	// The symbol table cannot overlap the headers, some linkers leave the
+	// address zero while still recording the number of entries.
+	if headersEnd := offset + int64(file.NumSections)*CoffSectionHeaderSize; file.NumSymbolTableEntries > 0 && int64(file.SymbolTableStartAddress) < headersEnd {
+		return nil, fmt.Errorf("%w: symbol table at offset %d with %d entries overlaps the headers ending at offset %d",
+			ErrInvalidSymbolTableAddress, file.SymbolTableStartAddress, file.NumSymbolTableEntries, headersEnd)
+	}
+
+	// Skip ahead to read the string table, unless nothing refers to it. When
+	// the symbols have been stripped the symbol table address may be stale,
+	// in which case the table must not be read at all.
+	var stringTable []byte
+	if file.NumSymbolTableEntries > 0 || hasLongSectionNames(r, offset, int(file.NumSections)) {
+		sr.Seek(int64(file.SymbolTableStartAddress)+(int64(file.NumSymbolTableEntries)*CoffSymbolEntrySize), 0)
+		stringTable, err = ioutil.ReadAll(io.LimitReader(sr, o.maxStringTableSize+1))
+		if err != nil {
+			return
+		}
+		if int64(len(stringTable)) > o.maxStringTableSize {
+			return nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrStringTableTooLarge, o.maxStringTableSize)
+		}
+
+		// The string table was read to the end of the file
+		file.fileSize = uint64(file.SymbolTableStartAddress) + uint64(file.NumSymbolTableEntries)*CoffSymbolEntrySize + uint64(len(stringTable))
+		if len(stringTable) >= 4 {
+			file.stringTableSize = uint64(o.byteOrder.Uint32(stringTable))
+		}
 	}
 
 	// Reset to beginning of section headers
 	sr.Seek(offset, 0)
 
+	if err = o.checkContext("before reading the section headers"); err != nil {
+		return nil, err
+	}
+
 	// Read all section headers
 	file.Sections = make([]*Section, file.NumSections)
 	for i := 0; i < len(file.Sections); i++ {
 		section := new(Section)
 		header := new(sectionHeader)
 
-		err = binary.Read(sr, binary.LittleEndian, &chars)
+		err = binary.Read(sr, o.byteOrder, &chars)
 		if err != nil {
 			return
 		}
-		err = binary.Read(sr, binary.LittleEndian, header)
+		err = binary.Read(sr, o.byteOrder, header)
 		if err != nil {
 			return
 		}
@@ -92,11 +210,15 @@ func NewFile(r io.ReaderAt) (file *File, err error) {
 		offset += int64(binary.Size(chars))
 		offset += int64(binary.Size(header))
 
-		name, err = getString(stringTable, chars)
+		name, err = getString(stringTable, chars, o.byteOrder)
 		if err != nil {
 			return
 		}
 
+		section.rawName = chars
+		if file.TargetID == 0x0097 || file.TargetID == 0x00A0 {
+			section.numLineNumbers = header.NumLineNumberEntries
+		}
 		section.SectionHeader = SectionHeader{
 			Name:                     name,
 			PhysicalAddress:          header.PhysicalAddress,
@@ -110,39 +232,81 @@ func NewFile(r io.ReaderAt) (file *File, err error) {
 		}
 
 		sr.Seek(0, 0)
-		section.sr = io.NewSectionReader(r, int64(section.RawDataAddress), int64(section.Size))
+		section.sr = io.NewSectionReader(r, int64(section.RawDataAddress), int64(section.Size)*file.TargetID.sizeUnit())
+		section.ReaderAt = section.sr
+
+		if o.strict {
+			if err = header.checkReserved(file.TargetID); err != nil {
+				return nil, fmt.Errorf("section %s: %w", name, err)
+			}
+		}
+		if o.strict && section.hasRawData() {
+			var b [1]byte
+			if _, err = section.ReadAt(b[:], section.ByteSize()-1); err != nil {
+				return nil, fmt.Errorf("section %s: %w", name, ErrSectionOutOfBounds)
+			}
+		}
+
+		// Unreadable relocation entries only fail the file in strict mode,
+		// otherwise they are reported by Section.Relocations
+		section.RelocationEntries, err = readRelocationEntries(r, &section.SectionHeader, file.TargetID, o.byteOrder)
+		if err != nil {
+			err = fmt.Errorf("section %s: relocations: %w", name, err)
+			if o.strict {
+				return nil, err
+			}
+			section.relocErr, err = err, nil
+		}
 		sr.Seek(offset, 0)
 		file.Sections[i] = section
+
+		o.reportProgress("sections", i, i+1, len(file.Sections))
+	}
+
+	if err = o.checkContext(fmt.Sprintf("after reading %d section headers", len(file.Sections))); err != nil {
+		return nil, err
 	}
 
 	// Read symbol table
 	sr.Seek(int64(file.SymbolTableStartAddress), 0)
 	file.symbols = make([]Symbol, 0, file.NumSymbolTableEntries)
+	file.symbolIndex = make([]int, 0, file.NumSymbolTableEntries)
 	for i := file.NumSymbolTableEntries; i > 0; i-- {
 		var sym symbol
 
-		err = binary.Read(sr, binary.LittleEndian, &chars)
+		index := file.NumSymbolTableEntries - i
+
+		err = binary.Read(sr, o.byteOrder, &chars)
 		if err != nil {
 			return
 		}
-		err = binary.Read(sr, binary.LittleEndian, &sym)
+		err = binary.Read(sr, o.byteOrder, &sym)
 		if err != nil {
 			return
 		}
 
-		name, err = getString(stringTable, chars)
+		name, err = getString(stringTable, chars, o.byteOrder)
 		if err != nil {
 			return
 		}
 
+		// Section numbers -2 (reserved), -1 (absolute) and 0 (undefined) do not
+		// refer to a section, anything else must be a valid 1-based index.
+		if sym.SectionNumber < -2 || int(sym.SectionNumber) > len(file.Sections) {
+			return nil, fmt.Errorf("symbol %d (%s): %w %d", index, name, ErrInvalidSectionNumber, sym.SectionNumber)
+		}
+
 		// Check if any auxiliary entries exist, these also count towards the
 		// total symbol entry count.
+		file.symbolIndex = append(file.symbolIndex, len(file.symbols))
+
 		var auxEntry *AuxiliaryEntry
 		if sym.NumAuxEntries == 1 {
 			i--
 			auxEntry = new(AuxiliaryEntry)
+			file.symbolIndex = append(file.symbolIndex, -1)
 
-			err = binary.Read(sr, binary.LittleEndian, auxEntry)
+			err = binary.Read(sr, o.byteOrder, auxEntry)
 			if err != nil {
 				return
 			}
@@ -152,19 +316,41 @@ func NewFile(r io.ReaderAt) (file *File, err error) {
 			Name:           name,
 			Value:          sym.Value,
 			SectionNumber:  sym.SectionNumber,
+			Type:           sym.Type,
 			StorageClass:   StorageClass(sym.StorageClass),
 			NumAuxEntries:  sym.NumAuxEntries,
 			AuxiliaryEntry: auxEntry,
 		})
+
+		o.reportProgress("symbols", int(index), int(file.NumSymbolTableEntries-i+1), int(file.NumSymbolTableEntries))
 	}
 
 	return
 }
 
-func getString(stringTable []byte, name [8]byte) (string, error) {
+// hasLongSectionNames checks if any of the n section headers starting at offset
+// store their name in the string table.
+func hasLongSectionNames(r io.ReaderAt, offset int64, n int) bool {
+	var chars [8]byte
+	for i := 0; i < n; i++ {
+		if _, err := r.ReadAt(chars[:], offset+int64(i)*CoffSectionHeaderSize); err != nil {
+			// Let the section header parsing report the error
+			return true
+		}
+		if chars[0] == 0 && chars[1] == 0 && chars[2] == 0 && chars[3] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func getString(stringTable []byte, name [8]byte, bo binary.ByteOrder) (string, error) {
 	if name[0] == 0 && name[1] == 0 && name[2] == 0 && name[3] == 0 {
-		// TODO: Offset into the string table
-		offset := (uint32(name[7]) << 24) | (uint32(name[6]) << 16) | (uint32(name[5]) << 8) | (uint32(name[4]) << 0)
+		// Offset into the string table, in the byte order of the file
+		offset := bo.Uint32(name[4:])
+		if uint64(offset) >= uint64(len(stringTable)) {
+			return "", ErrInvalidStringOffset
+		}
 
 		bs, err := bufio.NewReader(bytes.NewReader(stringTable[offset:])).ReadBytes(0x00)
 		if err != nil {
@@ -193,10 +379,220 @@ func Open(name string) (f *File, err error) {
 	return
 }
 
+// OpenMmap opens the named file using a read-only memory mapping, so that
+// section reads are copied directly from memory.
+func OpenMmap(name string) (f *File, err error) {
+	m, err := mmap.Open(name)
+	if err != nil {
+		return
+	}
+
+	f, err = NewFile(m)
+	if err != nil {
+		m.Close()
+		return
+	}
+
+	f.closer = m
+	return
+}
+
+// Symbols returns the symbol table. The returned slice shares its backing
+// array with the File and must be treated as read-only, use SymbolTable for a
+// copy that may be modified.
 func (f *File) Symbols() ([]Symbol, error) {
 	return f.symbols, nil
 }
 
+// ForEachSymbol calls fn for each symbol in symbol table order with its
+// position in Symbols, stopping early if fn returns false.
+func (f *File) ForEachSymbol(fn func(idx int, sym Symbol) bool) {
+	for i, sym := range f.symbols {
+		if !fn(i, sym) {
+			return
+		}
+	}
+}
+
+// OptionalHeader returns a copy of the optional file header, ok is false if
+// the file has none.
+func (f *File) OptionalHeader() (h OptionalFileHeader, ok bool) {
+	if f.OptionalFileHeader == nil {
+		return
+	}
+	return *f.OptionalFileHeader, true
+}
+
+// SymbolNames returns the names of the symbols in the order of Symbols, so
+// that indexes correspond.
+func (f *File) SymbolNames() []string {
+	names := make([]string, len(f.symbols))
+	for i, sym := range f.symbols {
+		names[i] = sym.Name
+	}
+	return names
+}
+
+// AbsoluteSymbols returns the symbols with section number -1, whose values
+// are constants rather than addresses in a section. These are often
+// linker-generated, such as _bss_size.
+func (f *File) AbsoluteSymbols() []Symbol {
+	var symbols []Symbol
+	for _, sym := range f.symbols {
+		if sym.SectionNumber == -1 {
+			symbols = append(symbols, sym)
+		}
+	}
+	return symbols
+}
+
+// Symbol returns the first symbol with the given name. It returns an error
+// wrapping ErrSymbolNotFound if there is none.
+func (f *File) Symbol(name string) (Symbol, error) {
+	for _, sym := range f.symbols {
+		if sym.Name == name {
+			return sym, nil
+		}
+	}
+	return Symbol{}, fmt.Errorf("symbol %s: %w", name, ErrSymbolNotFound)
+}
+
+// SymbolCount returns the number of symbols, not counting auxiliary entries.
+func (f *File) SymbolCount() int {
+	return len(f.symbols)
+}
+
+// SectionCount returns the number of sections.
+func (f *File) SectionCount() int {
+	return len(f.Sections)
+}
+
+// SymbolTable returns a copy of the symbol table which shares no memory with
+// the File, including the auxiliary entries. The caller owns the result.
+func (f *File) SymbolTable() SymbolTable {
+	symbols := make(SymbolTable, len(f.symbols))
+	copy(symbols, f.symbols)
+	for i := range symbols {
+		if aux := symbols[i].AuxiliaryEntry; aux != nil {
+			symbols[i].AuxiliaryEntry = new(AuxiliaryEntry)
+			*symbols[i].AuxiliaryEntry = *aux
+		}
+	}
+	return symbols
+}
+
+// OffsetOfSectionHeader returns the file offset of the section header with the
+// given index.
+func (f *File) OffsetOfSectionHeader(index int) int64 {
+	return CoffFileHeaderSize + int64(f.OptionalFileHeaderSize) + int64(index)*CoffSectionHeaderSize
+}
+
+// OffsetOfSymbol returns the file offset of the symbol table entry with the
+// given index. Auxiliary entries occupy an index of their own.
+func (f *File) OffsetOfSymbol(index int) int64 {
+	return int64(f.SymbolTableStartAddress) + int64(index)*CoffSymbolEntrySize
+}
+
+// SectionIndex returns a map from section name to index in Sections. When
+// several sections share a name, the map holds the last index. The map is
+// built on the first call and shared between calls, it must not be modified.
+func (f *File) SectionIndex() map[string]int {
+	f.sectionIndexOnce.Do(func() {
+		f.sectionIndex = make(map[string]int, len(f.Sections))
+		for i, s := range f.Sections {
+			f.sectionIndex[s.Name] = i
+		}
+	})
+	return f.sectionIndex
+}
+
+// SectionNames returns the names of the sections in file order.
+func (f *File) SectionNames() []string {
+	names := make([]string, len(f.Sections))
+	for i, s := range f.Sections {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Section returns the first section with the given name. It returns an error
+// wrapping ErrSectionNotFound if there is none.
+func (f *File) Section(name string) (*Section, error) {
+	for _, s := range f.Sections {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("section %s: %w", name, ErrSectionNotFound)
+}
+
+// SectionData returns the raw data of the first section with the given name,
+// or nil for sections without raw data such as .bss. It returns
+// ErrSectionNotFound if there is no such section.
+func (f *File) SectionData(name string) ([]byte, error) {
+	s, err := f.Section(name)
+	if err != nil || !s.hasRawData() {
+		return nil, err
+	}
+	data := make([]byte, s.ByteSize())
+	if _, err = s.sr.ReadAt(data, 0); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// TextSections returns the sections with STYP_TEXT set, in file order.
+func (f *File) TextSections() []*Section {
+	return f.sectionsWithFlags(STYP_TEXT)
+}
+
+// DataSections returns the sections with STYP_DATA set, in file order.
+func (f *File) DataSections() []*Section {
+	return f.sectionsWithFlags(STYP_DATA)
+}
+
+// BSSSections returns the sections with STYP_BSS set, in file order.
+func (f *File) BSSSections() []*Section {
+	return f.sectionsWithFlags(STYP_BSS)
+}
+
+// ConditionalSections returns the conditionally linked sections, those with
+// STYP_CLINK set, in file order.
+func (f *File) ConditionalSections() []*Section {
+	return f.sectionsWithFlags(STYP_CLINK)
+}
+
+func (f *File) sectionsWithFlags(flags SectionHeaderFlags) []*Section {
+	var sections []*Section
+	for _, s := range f.Sections {
+		if s.Flags&flags != 0 {
+			sections = append(sections, s)
+		}
+	}
+	return sections
+}
+
+// PageGroups returns the sections grouped by memory page number. On non-paged
+// devices all sections are on page 0, on paged devices page 0 is program
+// memory and the other pages are data memory.
+func (f *File) PageGroups() map[MemoryPage][]*Section {
+	groups := make(map[MemoryPage][]*Section)
+	for _, s := range f.Sections {
+		groups[s.MemoryPageNumber] = append(groups[s.MemoryPageNumber], s)
+	}
+	return groups
+}
+
+// SymbolByIndex returns the symbol at the given symbol table index, as used by
+// RelocationEntry.SymbolTableIndex. Auxiliary entries occupy an index of their
+// own, ok is false for those and for indexes past the end of the table.
+func (f *File) SymbolByIndex(index uint32) (sym Symbol, ok bool) {
+	if uint64(index) >= uint64(len(f.symbolIndex)) || f.symbolIndex[index] < 0 {
+		return
+	}
+	return f.symbols[f.symbolIndex[index]], true
+}
+
 func (f *File) Close() error {
 	if f.closer != nil {
 		return f.closer.Close()
@@ -207,6 +603,8 @@ func (f *File) Close() error {
 
 // A FileHeader represents a COFF file header.
 type FileHeader struct {
+	// Version is the version ID of the COFF file structure, see
+	// File.COFFVersion for its meaning.
 	Version                 uint16
 	NumSections             uint16
 	Timestamp               uint32
@@ -217,6 +615,48 @@ type FileHeader struct {
 	TargetID                TargetID
 }
 
+// RawHeader returns a copy of the file header bytes exactly as they were read.
+func (f *File) RawHeader() []byte {
+	return append([]byte(nil), f.rawHeader...)
+}
+
+// COFFVersion returns the version of the COFF file structure:
+//
+//	0 - COFF0, the original format, no longer produced by the TI tools.
+//	1 - COFF1, only produced for the C5400; section headers use 16-bit counts
+//	    and flags and an 8-bit memory page number.
+//	2 - COFF2, used by all other device families and the only layout
+//	    understood by NewFile.
+//
+// The TI tools store these as 0x00C0, 0x00C1 and 0x00C2 respectively, both
+// encodings are normalized to 0, 1 and 2.
+func (f *File) COFFVersion() uint16 {
+	if f.Version >= 0x00C0 && f.Version <= 0x00C2 {
+		return f.Version - 0x00C0
+	}
+	return f.Version
+}
+
+// IsVersion2 reports whether the file uses the COFF2 file structure.
+func (f *File) IsVersion2() bool {
+	return f.COFFVersion() == 2
+}
+
+// detectByteOrder returns the byte order of the raw file header. The target
+// ID is the only field whose value is known in advance, so a file whose
+// target ID is only valid when read big-endian is big-endian. Such files
+// have FLAG_BIG set, which byteOrderOf relies on to write them back.
+func detectByteOrder(raw []byte) binary.ByteOrder {
+	le := TargetID(binary.LittleEndian.Uint16(raw[20:]))
+	be := TargetID(binary.BigEndian.Uint16(raw[20:]))
+	if _, ok := targetIDMap[le]; !ok {
+		if _, ok := targetIDMap[be]; ok {
+			return binary.BigEndian
+		}
+	}
+	return binary.LittleEndian
+}
+
 // IsValidTargetID checks if the target ID matches those defined in the
 // TI-COFF specification.
 func IsValidTargetID(header *FileHeader) (valid bool) {
@@ -236,26 +676,81 @@ const (
 
 type TargetID uint16
 
-var targetIDMap = map[TargetID]string{
-	0x0097: "TMS470",
-	0x0098: "TMS320C5400",
-	0x0099: "TMS320C6000",
-	0x009C: "TMS320C5500",
-	0x009D: "TMS320C2800",
-	0x00A0: "MSP430",
-	0x00A1: "TMS320C5500+",
+// TargetInfo describes the device family of a target ID.
+type TargetInfo struct {
+	// Name is the name of the device family.
+	Name string
+
+	// WordSizeBits is the native word size of the CPU.
+	WordSizeBits int
+
+	// DataWordSizeBits is the size of the smallest addressable unit of
+	// data memory, 16 on the word-addressed devices.
+	DataWordSizeBits int
+
+	// IsLittleEndian is the default byte order of the device family, the
+	// FLAG_LITTLE and FLAG_BIG file header flags take precedence.
+	IsLittleEndian bool
+
+	// IsARM is set for ARM cores.
+	IsARM bool
+}
+
+var targetIDMap = map[TargetID]TargetInfo{
+	0x0097: {"TMS470", 32, 8, true, true},
+	0x0098: {"TMS320C5400", 16, 16, false, false},
+	0x0099: {"TMS320C6000", 32, 8, true, false},
+	0x009C: {"TMS320C5500", 16, 16, false, false},
+	0x009D: {"TMS320C2800", 32, 16, true, false},
+	0x00A0: {"MSP430", 16, 8, true, false},
+	0x00A1: {"TMS320C5500+", 16, 16, false, false},
+}
+
+// Info returns the description of the device family of the target ID, ok is
+// false if the target ID is unknown.
+func (tid TargetID) Info() (info TargetInfo, ok bool) {
+	info, ok = targetIDMap[tid]
+	return
 }
 
 func (tid TargetID) String() string {
 	var s string
-	if deviceFamily, exists := targetIDMap[tid]; exists {
-		s = deviceFamily
+	if info, exists := targetIDMap[tid]; exists {
+		s = info.Name
 	} else {
 		s = "Unknown"
 	}
 	return fmt.Sprintf("%s (0x%04X)", s, uint16(tid))
 }
 
+// UsesThumbInterworking reports whether the target is an ARM core (TMS470),
+// whose toolchain marks Thumb function addresses by setting the least
+// significant bit, as in the ARM ELF ABI.
+func (tid TargetID) UsesThumbInterworking() bool {
+	return tid == 0x0097
+}
+
+// PhysicalPC returns the instruction address of a code address of the target,
+// clearing the Thumb bit on TMS470 and leaving other targets unchanged. Symbol
+// values should be passed through PhysicalPC before being compared with
+// program counter values, as SymbolAt does.
+func (tid TargetID) PhysicalPC(addr uint32) uint32 {
+	if tid.UsesThumbInterworking() {
+		return addr &^ 1
+	}
+	return addr
+}
+
+// sizeUnit returns the number of bytes counted by one unit of the Size field
+// of a section header. C2800 and C5400 count 16-bit words, all other targets
+// count bytes, including C5500 despite its 16-bit data words.
+func (tid TargetID) sizeUnit() int64 {
+	if tid == 0x0098 || tid == 0x009D {
+		return 2
+	}
+	return 1
+}
+
 // An OptionalFileHeader represents a COFF file optional header.
 type OptionalFileHeader struct {
 	MagicNumber                 uint16
@@ -274,16 +769,76 @@ const OptionalFileHeaderMagicNumber uint16 = 0x0108
 type Section struct {
 	SectionHeader
 
-	io.ReaderAt
-	sr *io.SectionReader
+	// Embed ReaderAt for ReadAt method. Offsets are relative to the start of
+	// the section's raw data, not the file, and reads are bounded by the
+	// section size.
+	io.ReaderAt `json:"-"`
+	sr          *io.SectionReader
 
-	// TODO: Relocation information
+	// rawName is the name field of the section header, kept for Validate.
+	rawName [8]byte
+
+	// numLineNumbers is the number of line number entries, only recorded on
+	// TMS470 and MSP430.
+	numLineNumbers uint32
+
+	// RelocationEntries is nil if the entries could not be read, see
+	// Relocations.
+	RelocationEntries []RelocationEntry
+
+	// relocErr is the error reading the relocation entries.
+	relocErr error
+
+	// noData is set on sections decoded by ReadJSON, whose raw data is not
+	// available although the header records it.
+	noData bool
+}
+
+// ByteSize returns the size of the section's raw data in bytes, which is
+// twice Size on C2800 and C5400 where Size counts 16-bit words. It is zero
+// for sections without raw data, such as .bss.
+func (s *Section) ByteSize() int64 {
+	if !s.hasRawData() {
+		return 0
+	}
+	return s.sr.Size()
 }
 
+// Relocations returns the relocation entries of the section, or the error
+// that prevented reading them when the file was parsed, such as
+// ErrRelocationsOutOfBounds for a corrupt relocation pointer.
+func (s *Section) Relocations() ([]RelocationEntry, error) {
+	return s.RelocationEntries, s.relocErr
+}
+
+// Open returns a new ReadSeeker reading the section's raw data from its
+// start. Each call returns an independent reader with its own offset, so
+// goroutines can read the same section concurrently if each calls Open.
 func (s *Section) Open() io.ReadSeeker {
 	return io.NewSectionReader(s.sr, 0, 1<<63-1)
 }
 
+// IsConditionallyLinked reports whether STYP_CLINK is set, in which case the
+// linker only includes the section when it is referenced. Such sections are
+// counted in object files but may not contribute to the size of the final
+// executable.
+func (s *Section) IsConditionallyLinked() bool {
+	return s.Flags&STYP_CLINK != 0
+}
+
+// IsNoLoad reports whether STYP_NOLOAD is set. The section is allocated and
+// relocated but not loaded into the target, so it must not be programmed into
+// flash memory.
+func (s *Section) IsNoLoad() bool {
+	return s.Flags&STYP_NOLOAD != 0
+}
+
+// IsDummySection reports whether STYP_DSECT is set. The section is relocated
+// but not allocated, so it takes no part in the memory layout.
+func (s *Section) IsDummySection() bool {
+	return s.Flags&STYP_DSECT != 0
+}
+
 // A SectionHeader represent a COFF file code section header.
 type SectionHeader struct {
 	Name                     string
@@ -294,7 +849,49 @@ type SectionHeader struct {
 	RelocationEntriesAddress uint32
 	NumRelocationEntries     uint32
 	Flags                    SectionHeaderFlags
-	MemoryPageNumber         uint16
+
+	// MemoryPageNumber selects the memory page of the section on paged
+	// devices (C5400, C5500), where it should be treated as part of the
+	// section's address. It is 0 for non-paged devices.
+	MemoryPageNumber MemoryPage
+}
+
+// MemoryPage is a memory page number of a paged device.
+type MemoryPage = uint16
+
+// pageBits is the number of address bits within a memory page of the paged
+// devices, which have 64K pages. The page number occupies the bits above.
+const pageBits = 16
+
+// EffectiveAddress returns the physical address of the section combined with
+// its memory page number, as page<<16 + address. On non-paged devices it is
+// the physical address.
+func (h *SectionHeader) EffectiveAddress() uint64 {
+	return uint64(h.MemoryPageNumber)<<pageBits + uint64(h.PhysicalAddress)
+}
+
+// Pages returns the memory pages touched by the section, computed from its
+// EffectiveAddress. Sections whose address range crosses a 64K page boundary
+// continue on the following pages. Sections of size zero are on a single
+// page.
+func (h *SectionHeader) Pages() []MemoryPage {
+	first := h.EffectiveAddress() >> pageBits
+	last := first
+	if h.Size > 0 {
+		last = (h.EffectiveAddress() + uint64(h.Size) - 1) >> pageBits
+	}
+
+	pages := make([]MemoryPage, 0, last-first+1)
+	for p := first; p <= last; p++ {
+		pages = append(pages, MemoryPage(p))
+	}
+	return pages
+}
+
+// Alignment returns the section alignment in bytes, encoded as a power of two
+// in bits 8-11 of the flags.
+func (h *SectionHeader) Alignment() uint32 {
+	return 1 << ((h.Flags >> 8) & 0xF)
 }
 
 type SectionHeaderFlags uint32
@@ -324,18 +921,31 @@ type sectionHeader struct {
 	Size                     uint32
 	RawDataAddress           uint32
 	RelocationEntriesAddress uint32
-	_                        uint32
+	Reserved                 uint32
 	NumRelocationEntries     uint32
-	_                        uint32
+	NumLineNumberEntries     uint32 // Reserved except on TMS470 and MSP430
 	Flags                    uint32
-	_                        uint16
+	Reserved2                uint16
 	MemoryPageNumber         uint16
 }
 
+// checkReserved returns ErrReservedFieldSet if a reserved field of the
+// section header of target tid is not zero.
+func (h *sectionHeader) checkReserved(tid TargetID) error {
+	hasLineNumbers := tid == 0x0097 || tid == 0x00A0
+	if h.Reserved != 0 || h.Reserved2 != 0 || (!hasLineNumbers && h.NumLineNumberEntries != 0) {
+		return ErrReservedFieldSet
+	}
+	return nil
+}
+
 type Symbol struct {
 	Name          string
 	Value         uint32
 	SectionNumber int16
+	// Type encodes the base type of the symbol in its low 4 bits and
+	// the derived types in 2-bit groups above them.
+	Type          uint16
 	StorageClass  StorageClass
 	NumAuxEntries uint8
 	// AuxiliaryEntry will be non-nil if NumAuxEntries == 1
@@ -440,11 +1050,110 @@ func (c StorageClass) String() string {
 	return fmt.Sprintf("%s (%d)", s, uint8(c))
 }
 
+// BaseType returns the base type of the symbol.
+func (s *Symbol) BaseType() SymbolBaseType {
+	return SymbolBaseType(s.Type & 0xF)
+}
+
+// DerivedType returns the first derived type of the symbol, e.g. DT_FCN for
+// a function returning the base type.
+func (s *Symbol) DerivedType() SymbolDerivedType {
+	return SymbolDerivedType((s.Type >> 4) & 0x3)
+}
+
+type SymbolBaseType uint8
+
+const (
+	T_NULL   SymbolBaseType = 0  // Type not assigned
+	T_VOID                  = 1  // Void
+	T_CHAR                  = 2  // Character
+	T_SHORT                 = 3  // Short integer
+	T_INT                   = 4  // Integer
+	T_LONG                  = 5  // Long integer
+	T_FLOAT                 = 6  // Single-precision floating point
+	T_DOUBLE                = 7  // Double-precision floating point
+	T_STRUCT                = 8  // Structure
+	T_UNION                 = 9  // Union
+	T_ENUM                  = 10 // Enumeration
+	T_MOE                   = 11 // Member of enumeration
+	T_UCHAR                 = 12 // Unsigned character
+	T_USHORT                = 13 // Unsigned short integer
+	T_UINT                  = 14 // Unsigned integer
+	T_ULONG                 = 15 // Unsigned long integer
+)
+
+func (t SymbolBaseType) String() string {
+	var s string
+	switch t {
+	default:
+		s = "Unknown"
+	case T_NULL:
+		s = "T_NULL"
+	case T_VOID:
+		s = "T_VOID"
+	case T_CHAR:
+		s = "T_CHAR"
+	case T_SHORT:
+		s = "T_SHORT"
+	case T_INT:
+		s = "T_INT"
+	case T_LONG:
+		s = "T_LONG"
+	case T_FLOAT:
+		s = "T_FLOAT"
+	case T_DOUBLE:
+		s = "T_DOUBLE"
+	case T_STRUCT:
+		s = "T_STRUCT"
+	case T_UNION:
+		s = "T_UNION"
+	case T_ENUM:
+		s = "T_ENUM"
+	case T_MOE:
+		s = "T_MOE"
+	case T_UCHAR:
+		s = "T_UCHAR"
+	case T_USHORT:
+		s = "T_USHORT"
+	case T_UINT:
+		s = "T_UINT"
+	case T_ULONG:
+		s = "T_ULONG"
+	}
+	return fmt.Sprintf("%s (%d)", s, uint8(t))
+}
+
+type SymbolDerivedType uint8
+
+const (
+	DT_NON SymbolDerivedType = 0 // No derived type
+	DT_PTR                   = 1 // Pointer
+	DT_FCN                   = 2 // Function
+	DT_ARY                   = 3 // Array
+)
+
+func (t SymbolDerivedType) String() string {
+	var s string
+	switch t {
+	default:
+		s = "Unknown"
+	case DT_NON:
+		s = "DT_NON"
+	case DT_PTR:
+		s = "DT_PTR"
+	case DT_FCN:
+		s = "DT_FCN"
+	case DT_ARY:
+		s = "DT_ARY"
+	}
+	return fmt.Sprintf("%s (%d)", s, uint8(t))
+}
+
 type symbol struct {
 	// name [8]byte
 	Value         uint32
 	SectionNumber int16
-	_             uint16
+	Type          uint16
 	StorageClass  uint8
 	NumAuxEntries uint8
 }
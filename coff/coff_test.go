@@ -0,0 +1,293 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testBuilder returns a Builder for a little-endian MSP430 executable with a
+// .text, a .data and a .bss section.
+func testBuilder() *Builder {
+	return &Builder{
+		FileHeader: FileHeader{
+			Version:  0xC2,
+			Flags:    FLAG_LITTLE | FLAG_EXEC,
+			TargetID: 0x00A0,
+		},
+		OptionalFileHeader: &OptionalFileHeader{
+			MagicNumber:                 OptionalFileHeaderMagicNumber,
+			ExecuteableCodeSize:         8,
+			InitializedDataSize:         4,
+			UninitializedDataSize:       16,
+			EntryPoint:                  0x1002,
+			BeginAddressExecutableCode:  0x1000,
+			BeginAddressInitializedData: 0x2000,
+		},
+		Sections: []*BuilderSection{
+			{
+				SectionHeader: SectionHeader{Name: ".text", PhysicalAddress: 0x1000, VirtualAddress: 0x1000, Flags: STYP_TEXT},
+				Data:          []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			{
+				SectionHeader: SectionHeader{Name: ".data", PhysicalAddress: 0x2000, VirtualAddress: 0x2000, Flags: STYP_DATA},
+				Data:          []byte{0xAA, 0xBB, 0xCC, 0xDD},
+			},
+			{
+				SectionHeader: SectionHeader{Name: ".bss", PhysicalAddress: 0x3000, VirtualAddress: 0x3000, Size: 16, Flags: STYP_BSS},
+			},
+		},
+		Symbols: []Symbol{
+			{Name: ".text", Value: 0x1000, SectionNumber: 1, StorageClass: C_STAT, AuxiliaryEntry: &AuxiliaryEntry{Size: 8}},
+			{Name: "_main", Value: 0x1002, SectionNumber: 1, StorageClass: C_EXT},
+			{Name: "a_long_symbol_name", Value: 0x2000, SectionNumber: 2, StorageClass: C_EXT},
+		},
+	}
+}
+
+// testFileBytes returns the encoding of testBuilder.
+func testFileBytes(t *testing.T) []byte {
+	data, err := testBuilder().Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testFile writes the encoding of testBuilder to a temporary file and opens it.
+func testFile(t *testing.T) *File {
+	name := filepath.Join(t.TempDir(), "test.out")
+	if err := os.WriteFile(name, testFileBytes(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestSectionReadAt(t *testing.T) {
+	f := testFile(t)
+	s := f.Sections[1]
+
+	p := make([]byte, 2)
+	n, err := s.ReadAt(p, 1)
+	if n != 2 || err != nil || !bytes.Equal(p, []byte{0xBB, 0xCC}) {
+		t.Errorf("ReadAt(1) = %d, %v, %x", n, err, p)
+	}
+
+	p = make([]byte, 4)
+	n, err = s.ReadAt(p, 2)
+	if n != 2 || err != io.EOF || !bytes.Equal(p[:n], []byte{0xCC, 0xDD}) {
+		t.Errorf("ReadAt(2) = %d, %v, %x, want 2, io.EOF", n, err, p[:n])
+	}
+
+	n, err = s.ReadAt(p, 4)
+	if n != 0 || err != io.EOF {
+		t.Errorf("ReadAt(4) = %d, %v, want 0, io.EOF", n, err)
+	}
+}
+
+func TestCOFFRoundTrip(t *testing.T) {
+	data := testFileBytes(t)
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := NewBuilder(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = b.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("rebuilt file differs from the original")
+	}
+
+	g, err := NewFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Sections) != len(f.Sections) {
+		t.Fatalf("rebuilt file has %d sections, want %d", len(g.Sections), len(f.Sections))
+	}
+	for i, s := range f.Sections {
+		gs := g.Sections[i]
+		if gs.Name != s.Name || gs.PhysicalAddress != s.PhysicalAddress || gs.VirtualAddress != s.VirtualAddress ||
+			gs.Size != s.Size || gs.Flags != s.Flags {
+			t.Errorf("section %d: got %+v, want %+v", i, gs.SectionHeader, s.SectionHeader)
+		}
+	}
+	if got, want := g.SymbolNames(), f.SymbolNames(); len(got) != len(want) {
+		t.Errorf("symbol names %q, want %q", got, want)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("symbol %d: name %q, want %q", i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestNewFileInvalidOptionalMagic(t *testing.T) {
+	b := testBuilder()
+	b.OptionalFileHeader.MagicNumber = 0x0109
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := binary.LittleEndian.Uint16(data[16:]); size != 28 {
+		t.Fatalf("OptionalFileHeaderSize = %d, want 28", size)
+	}
+
+	if _, err = NewFile(bytes.NewReader(data)); !errors.Is(err, ErrInvalidOptionalMagic) {
+		t.Errorf("NewFile error = %v, want ErrInvalidOptionalMagic", err)
+	}
+}
+
+func TestForEachSymbolAllocs(t *testing.T) {
+	f := testFile(t)
+
+	var found bool
+	allocs := testing.AllocsPerRun(100, func() {
+		found = false
+		f.ForEachSymbol(func(idx int, sym Symbol) bool {
+			if sym.StorageClass == C_EXT {
+				found = true
+				return false
+			}
+			return true
+		})
+	})
+	if !found {
+		t.Error("no external symbol found")
+	}
+	if allocs != 0 {
+		t.Errorf("ForEachSymbol allocated %v times per run, want 0", allocs)
+	}
+
+	if allocs = testing.AllocsPerRun(100, func() { f.SymbolTable() }); allocs == 0 {
+		t.Error("SymbolTable did not allocate, the comparison is meaningless")
+	}
+}
+
+// benchSymbolCount is the number of symbols of benchFile.
+const benchSymbolCount = 10000
+
+// benchFile returns a file with benchSymbolCount function symbols 4 bytes
+// apart in its .text section.
+func benchFile(b *testing.B) *File {
+	builder := testBuilder()
+	text := builder.Sections[0]
+	text.Data = make([]byte, 4*benchSymbolCount)
+	builder.Symbols = make([]Symbol, benchSymbolCount)
+	for i := range builder.Symbols {
+		builder.Symbols[i] = Symbol{
+			Name:          fmt.Sprintf("_func%05d", i),
+			Value:         text.PhysicalAddress + uint32(4*i),
+			SectionNumber: 1,
+			StorageClass:  C_EXT,
+		}
+	}
+
+	data, err := builder.Bytes()
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+func BenchmarkSymbolByName(b *testing.B) {
+	f := benchFile(b)
+	names := f.SymbolNames()
+
+	b.Run("Symbol", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := f.Symbol(names[i%len(names)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Map", func(b *testing.B) {
+		byName := make(map[string]Symbol, len(names))
+		f.ForEachSymbol(func(idx int, sym Symbol) bool {
+			byName[sym.Name] = sym
+			return true
+		})
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, ok := byName[names[i%len(names)]]; !ok {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+}
+
+func BenchmarkSymbolAt(b *testing.B) {
+	f := benchFile(b)
+	addr := func(i int) uint32 {
+		return 0x1000 + uint32(i*4%(4*benchSymbolCount)) + 2
+	}
+
+	b.Run("Linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			a := addr(i)
+			found := false
+			f.ForEachSymbol(func(idx int, sym Symbol) bool {
+				if sym.Value <= a && a-sym.Value < 4 {
+					found = true
+					return false
+				}
+				return true
+			})
+			if !found {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+	b.Run("Indexed", func(b *testing.B) {
+		f.SymbolAt(0)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, ok := f.SymbolAt(addr(i)); !ok {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+}
+
+func TestSectionHeaderPages(t *testing.T) {
+	for _, test := range []struct {
+		h     SectionHeader
+		addr  uint64
+		pages []MemoryPage
+	}{
+		{SectionHeader{PhysicalAddress: 0xFE00, Size: 1024}, 0xFE00, []MemoryPage{0, 1}},
+		{SectionHeader{PhysicalAddress: 0x1000, Size: 16, MemoryPageNumber: 2}, 0x21000, []MemoryPage{2}},
+		{SectionHeader{PhysicalAddress: 0x12000, MemoryPageNumber: 1}, 0x22000, []MemoryPage{2}},
+	} {
+		if addr := test.h.EffectiveAddress(); addr != test.addr {
+			t.Errorf("%+v: EffectiveAddress() = %#x, want %#x", test.h, addr, test.addr)
+		}
+		if pages := test.h.Pages(); fmt.Sprint(pages) != fmt.Sprint(test.pages) {
+			t.Errorf("%+v: Pages() = %v, want %v", test.h, pages, test.pages)
+		}
+	}
+}
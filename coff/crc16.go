@@ -0,0 +1,50 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bufio"
+	"io"
+)
+
+// crc16Table is the lookup table of CRC-16-CCITT, polynomial 0x1021, most
+// significant bit first.
+var crc16Table = func() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return
+}()
+
+// CRC16 returns the CRC-16-CCITT checksum of the section data, with the
+// polynomial 0x1021 and initial value 0xFFFF, as computed by the TI flash
+// programming tools to verify each section after programming. Sections
+// without raw data, such as .bss, have nothing to checksum and return the
+// initial value.
+func (s *Section) CRC16() (uint16, error) {
+	crc := uint16(0xFFFF)
+	if !s.hasRawData() {
+		return crc, nil
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(s, 0, s.ByteSize()))
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return crc, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^b]
+	}
+}
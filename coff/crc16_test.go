@@ -0,0 +1,26 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import "testing"
+
+func TestSectionCRC16(t *testing.T) {
+	f := testFile(t)
+	for _, test := range []struct {
+		section string
+		crc     uint16
+	}{
+		{".text", 0x4792},
+		{".bss", 0xFFFF},
+	} {
+		s, err := f.Section(test.section)
+		if err != nil {
+			t.Fatal(err)
+		}
+		crc, err := s.CRC16()
+		if err != nil || crc != test.crc {
+			t.Errorf("%s: CRC16() = %#04x, %v, want %#04x", test.section, crc, err, test.crc)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"fmt"
+)
+
+type DifferenceKind int
+
+const (
+	SectionAdded DifferenceKind = iota
+	SectionRemoved
+	SectionModified
+	SymbolAdded
+	SymbolRemoved
+	SymbolMoved
+)
+
+func (k DifferenceKind) String() string {
+	switch k {
+	case SectionAdded:
+		return "SectionAdded"
+	case SectionRemoved:
+		return "SectionRemoved"
+	case SectionModified:
+		return "SectionModified"
+	case SymbolAdded:
+		return "SymbolAdded"
+	case SymbolRemoved:
+		return "SymbolRemoved"
+	case SymbolMoved:
+		return "SymbolMoved"
+	}
+	return fmt.Sprintf("DifferenceKind%d", int(k))
+}
+
+// A Difference is a change between two files found by DiffFiles.
+type Difference struct {
+	Kind DifferenceKind
+
+	// Name is the name of the section or symbol that changed.
+	Name string
+
+	// OldSize and NewSize are the section sizes for SectionModified.
+	OldSize uint32
+	NewSize uint32
+
+	// OldAddress and NewAddress are the section addresses for
+	// SectionModified, and the symbol values for SymbolMoved.
+	OldAddress uint32
+	NewAddress uint32
+
+	// AddressChanged is set for SectionModified if the section moved.
+	AddressChanged bool
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case SectionModified:
+		return fmt.Sprintf("%v %s: size %d -> %d, address 0x%08X -> 0x%08X",
+			d.Kind, d.Name, d.OldSize, d.NewSize, d.OldAddress, d.NewAddress)
+	case SymbolMoved:
+		return fmt.Sprintf("%v %s: 0x%08X -> 0x%08X", d.Kind, d.Name, d.OldAddress, d.NewAddress)
+	}
+	return fmt.Sprintf("%v %s", d.Kind, d.Name)
+}
+
+// DiffFiles compares the sections and symbols of two files by name. Sections
+// are modified when their size or physical address changed and symbols moved
+// when their value changed. When names are duplicated only the first section
+// or symbol with the name is compared.
+func DiffFiles(a, b *File) []Difference {
+	var diffs []Difference
+
+	oldSections := sectionsByName(a)
+	newSections := sectionsByName(b)
+	for _, s := range a.Sections {
+		n, ok := newSections[s.Name]
+		switch {
+		case oldSections[s.Name] != s:
+			// Duplicate name
+		case !ok:
+			diffs = append(diffs, Difference{Kind: SectionRemoved, Name: s.Name})
+		case s.Size != n.Size || s.PhysicalAddress != n.PhysicalAddress:
+			diffs = append(diffs, Difference{
+				Kind:           SectionModified,
+				Name:           s.Name,
+				OldSize:        s.Size,
+				NewSize:        n.Size,
+				OldAddress:     s.PhysicalAddress,
+				NewAddress:     n.PhysicalAddress,
+				AddressChanged: s.PhysicalAddress != n.PhysicalAddress,
+			})
+		}
+	}
+	for _, s := range b.Sections {
+		if _, ok := oldSections[s.Name]; !ok && newSections[s.Name] == s {
+			diffs = append(diffs, Difference{Kind: SectionAdded, Name: s.Name})
+		}
+	}
+
+	oldSymbols := symbolsByName(a)
+	newSymbols := symbolsByName(b)
+	for i, sym := range a.symbols {
+		n, ok := newSymbols[sym.Name]
+		switch {
+		case oldSymbols[sym.Name] != i:
+			// Duplicate name
+		case !ok:
+			diffs = append(diffs, Difference{Kind: SymbolRemoved, Name: sym.Name})
+		case sym.Value != b.symbols[n].Value:
+			diffs = append(diffs, Difference{
+				Kind:       SymbolMoved,
+				Name:       sym.Name,
+				OldAddress: sym.Value,
+				NewAddress: b.symbols[n].Value,
+			})
+		}
+	}
+	for i, sym := range b.symbols {
+		if _, ok := oldSymbols[sym.Name]; !ok && newSymbols[sym.Name] == i {
+			diffs = append(diffs, Difference{Kind: SymbolAdded, Name: sym.Name})
+		}
+	}
+
+	return diffs
+}
+
+// sectionsByName maps section names to the first section with the name.
+func sectionsByName(f *File) map[string]*Section {
+	m := make(map[string]*Section, len(f.Sections))
+	for _, s := range f.Sections {
+		if _, ok := m[s.Name]; !ok {
+			m[s.Name] = s
+		}
+	}
+	return m
+}
+
+// symbolsByName maps symbol names to the index of the first symbol with the
+// name.
+func symbolsByName(f *File) map[string]int {
+	m := make(map[string]int, len(f.symbols))
+	for i, sym := range f.symbols {
+		if _, ok := m[sym.Name]; !ok {
+			m[sym.Name] = i
+		}
+	}
+	return m
+}
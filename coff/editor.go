@@ -0,0 +1,129 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import "fmt"
+
+// A FileEditor modifies a copy of a parsed COFF file, leaving the File itself
+// unchanged. Build encodes the modified file.
+type FileEditor struct {
+	b *Builder
+}
+
+// NewFileEditor creates a FileEditor holding a copy of the contents of f.
+func NewFileEditor(f *File) (*FileEditor, error) {
+	b, err := NewBuilder(f)
+	if err != nil {
+		return nil, err
+	}
+	return &FileEditor{b}, nil
+}
+
+// SetSymbolValue sets the value of every symbol with the given name. It
+// returns ErrSymbolNotFound if there is none.
+func (e *FileEditor) SetSymbolValue(name string, value uint32) error {
+	found := false
+	for i := range e.b.Symbols {
+		if e.b.Symbols[i].Name == name {
+			e.b.Symbols[i].Value = value
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("symbol %s: %w", name, ErrSymbolNotFound)
+	}
+	return nil
+}
+
+// PatchSectionData overwrites the raw data of the named section with data,
+// starting at offset. The data must lie within the section.
+func (e *FileEditor) PatchSectionData(sectionName string, offset uint32, data []byte) error {
+	s, _, err := e.section(sectionName)
+	if err != nil {
+		return err
+	}
+	if s.Data == nil {
+		return fmt.Errorf("section %s: no raw data", sectionName)
+	}
+	if uint64(offset)+uint64(len(data)) > uint64(len(s.Data)) {
+		return fmt.Errorf("section %s: patch [%d, %d) exceeds size %d", sectionName, offset, uint64(offset)+uint64(len(data)), len(s.Data))
+	}
+	copy(s.Data[offset:], data)
+	return nil
+}
+
+// RemoveSection removes the named section together with its section symbol,
+// renumbering the remaining sections and symbols. It fails if any other
+// symbol is defined in the section, or if a relocation entry refers to a
+// symbol being removed.
+func (e *FileEditor) RemoveSection(name string) error {
+	_, i, err := e.section(name)
+	if err != nil {
+		return err
+	}
+	number := int16(i + 1)
+
+	// Map the symbol table indexes of the kept symbols to their new indexes
+	var (
+		symbols            []Symbol
+		indexes            = make(map[uint32]uint32)
+		oldIndex, newIndex uint32
+	)
+	for _, sym := range e.b.Symbols {
+		entries := 1 + uint32(sym.NumAuxEntries)
+		if sym.SectionNumber == number {
+			if sym.Name != name {
+				return fmt.Errorf("section %s: symbol %s is defined in the section", name, sym.Name)
+			}
+			oldIndex += entries
+			continue
+		}
+		if sym.SectionNumber > number {
+			sym.SectionNumber--
+		}
+		indexes[oldIndex] = newIndex
+		symbols = append(symbols, sym)
+		oldIndex += entries
+		newIndex += entries
+	}
+
+	sections := append(e.b.Sections[:i:i], e.b.Sections[i+1:]...)
+	relocs := make([][]RelocationEntry, len(sections))
+	for j, s := range sections {
+		relocs[j] = append([]RelocationEntry(nil), s.RelocationEntries...)
+		for k, r := range relocs[j] {
+			if r.IsInternal() {
+				continue
+			}
+			index, ok := indexes[r.SymbolTableIndex]
+			if !ok {
+				return fmt.Errorf("section %s: relocation in %s refers to removed symbol %d", name, s.Name, r.SymbolTableIndex)
+			}
+			relocs[j][k].SymbolTableIndex = index
+		}
+	}
+
+	for j, s := range sections {
+		s.RelocationEntries = relocs[j]
+	}
+	e.b.Sections = sections
+	e.b.Symbols = symbols
+	return nil
+}
+
+// Build returns the encoded modified file.
+func (e *FileEditor) Build() ([]byte, error) {
+	return e.b.Bytes()
+}
+
+// section returns the named section and its index. It returns
+// ErrSectionNotFound if there is none.
+func (e *FileEditor) section(name string) (*BuilderSection, int, error) {
+	for i, s := range e.b.Sections {
+		if s.Name == name {
+			return s, i, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("section %s: %w", name, ErrSectionNotFound)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// elfMachines maps target IDs to ELF machine types.
+var elfMachines = map[TargetID]elf.Machine{
+	0x0097: elf.EM_ARM,
+	0x0099: elf.EM_TI_C6000,
+	0x009C: elf.EM_TI_C5500,
+	0x009D: elf.EM_TI_C2000,
+	0x00A0: elf.EM_MSP430,
+	0x00A1: elf.EM_TI_C5500,
+}
+
+// WriteELF writes the file to w as a 32-bit relocatable ELF file, so that it
+// can be inspected with the GNU binutils. Each COFF section becomes an ELF
+// section with the same index, followed by the symbol table.
+//
+// The conversion is limited: relocation entries, line numbers and debugging
+// information (DWARF) are not converted, and the ELF header flags are left
+// zero, so tools relying on a particular ABI version may reject the file.
+func (f *File) WriteELF(w io.Writer) error {
+	machine, ok := elfMachines[f.TargetID]
+	if !ok {
+		return fmt.Errorf("no ELF machine type for target %v", f.TargetID)
+	}
+
+	var (
+		bo   = f.byteOrder
+		data = elf.ELFDATA2LSB
+	)
+	if bo == binary.BigEndian {
+		data = elf.ELFDATA2MSB
+	}
+
+	var (
+		body     bytes.Buffer
+		shstrtab = []byte{0}
+		headers  = []elf.Section32{{}}
+	)
+	addString := func(table *[]byte, s string) uint32 {
+		off := uint32(len(*table))
+		*table = append(*table, s...)
+		*table = append(*table, 0)
+		return off
+	}
+	headerSize := uint32(binary.Size(elf.Header32{}))
+	align := func() {
+		for (headerSize+uint32(body.Len()))%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	// Sections
+	for _, s := range f.Sections {
+		h := elf.Section32{
+			Name:      addString(&shstrtab, s.Name),
+			Type:      uint32(elf.SHT_PROGBITS),
+			Addr:      s.PhysicalAddress,
+			Size:      uint32(int64(s.Size) * f.TargetID.sizeUnit()),
+			Addralign: s.Alignment(),
+		}
+		switch {
+		case s.Flags&(STYP_DSECT|STYP_COPY|STYP_PAD) != 0:
+		case s.Flags&STYP_TEXT != 0:
+			h.Flags = uint32(elf.SHF_ALLOC | elf.SHF_EXECINSTR)
+		case s.Flags&(STYP_DATA|STYP_BSS) != 0:
+			h.Flags = uint32(elf.SHF_ALLOC | elf.SHF_WRITE)
+		}
+
+		if s.hasRawData() && s.Flags&STYP_BSS == 0 {
+			align()
+			h.Off = headerSize + uint32(body.Len())
+			if _, err := io.Copy(&body, io.NewSectionReader(s.sr, 0, s.ByteSize())); err != nil {
+				return err
+			}
+		} else {
+			h.Type = uint32(elf.SHT_NOBITS)
+			h.Off = headerSize + uint32(body.Len())
+		}
+		headers = append(headers, h)
+	}
+
+	// Symbol table, local symbols must precede global ones
+	var (
+		strtab        = []byte{0}
+		locals        = []elf.Sym32{{}}
+		globals       []elf.Sym32
+		symtabIndex   = uint32(len(headers))
+		strtabIndex   = symtabIndex + 1
+		shstrtabIndex = symtabIndex + 2
+	)
+	for _, sym := range f.symbols {
+		esym := elf.Sym32{
+			Name:  addString(&strtab, sym.Name),
+			Value: sym.Value,
+		}
+		if sym.AuxiliaryEntry != nil {
+			esym.Size = sym.AuxiliaryEntry.Size
+		}
+
+		var section *Section
+		switch {
+		case sym.SectionNumber > 0 && int(sym.SectionNumber) <= len(f.Sections):
+			section = f.Sections[sym.SectionNumber-1]
+			esym.Shndx = uint16(sym.SectionNumber)
+		case sym.SectionNumber == 0:
+			esym.Shndx = uint16(elf.SHN_UNDEF)
+		default:
+			esym.Shndx = uint16(elf.SHN_ABS)
+		}
+
+		typ := elf.STT_NOTYPE
+		switch {
+		case sym.StorageClass == C_FILE:
+			typ = elf.STT_FILE
+		case section != nil && sym.Name == section.Name:
+			typ = elf.STT_SECTION
+			esym.Name = 0
+		case section != nil && section.Flags&STYP_TEXT != 0:
+			typ = elf.STT_FUNC
+		case section != nil && section.Flags&(STYP_DATA|STYP_BSS) != 0:
+			typ = elf.STT_OBJECT
+		}
+
+		if sym.StorageClass == C_EXT || sym.StorageClass == C_EXTLAB {
+			esym.Info = elf.ST_INFO(elf.STB_GLOBAL, typ)
+			globals = append(globals, esym)
+		} else {
+			esym.Info = elf.ST_INFO(elf.STB_LOCAL, typ)
+			locals = append(locals, esym)
+		}
+	}
+
+	align()
+	symtab := elf.Section32{
+		Name:      addString(&shstrtab, ".symtab"),
+		Type:      uint32(elf.SHT_SYMTAB),
+		Off:       headerSize + uint32(body.Len()),
+		Link:      strtabIndex,
+		Info:      uint32(len(locals)),
+		Addralign: 4,
+		Entsize:   uint32(binary.Size(elf.Sym32{})),
+	}
+	binary.Write(&body, bo, locals)
+	binary.Write(&body, bo, globals)
+	symtab.Size = headerSize + uint32(body.Len()) - symtab.Off
+	headers = append(headers, symtab)
+
+	headers = append(headers, elf.Section32{
+		Name:      addString(&shstrtab, ".strtab"),
+		Type:      uint32(elf.SHT_STRTAB),
+		Off:       headerSize + uint32(body.Len()),
+		Size:      uint32(len(strtab)),
+		Addralign: 1,
+	})
+	body.Write(strtab)
+
+	shstrtabHeader := elf.Section32{
+		Name:      addString(&shstrtab, ".shstrtab"),
+		Type:      uint32(elf.SHT_STRTAB),
+		Off:       headerSize + uint32(body.Len()),
+		Addralign: 1,
+	}
+	shstrtabHeader.Size = uint32(len(shstrtab))
+	headers = append(headers, shstrtabHeader)
+	body.Write(shstrtab)
+
+	align()
+	header := elf.Header32{
+		Type:      uint16(elf.ET_REL),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Shoff:     headerSize + uint32(body.Len()),
+		Ehsize:    uint16(headerSize),
+		Shentsize: uint16(binary.Size(elf.Section32{})),
+		Shnum:     uint16(len(headers)),
+		Shstrndx:  uint16(shstrtabIndex),
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS32)
+	header.Ident[elf.EI_DATA] = byte(data)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	if f.OptionalFileHeader != nil {
+		header.Entry = f.OptionalFileHeader.EntryPoint
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, bo, &header)
+	out.Write(body.Bytes())
+	binary.Write(&out, bo, headers)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
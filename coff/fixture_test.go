@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+// TestMSP430Fixture parses testdata/msp430.out, which is written by
+// testdata/mkmsp430.go without going through Builder. It is laid out after
+// the TI specification, not produced by cl430.
+func TestMSP430Fixture(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "msp430.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.TargetID != 0x00A0 || f.Flags != FLAG_RELFLG|FLAG_EXEC|FLAG_LITTLE {
+		t.Errorf("TargetID, Flags = %#x, %#x", f.TargetID, f.Flags)
+	}
+	if h, ok := f.OptionalHeader(); !ok || h.EntryPoint != 0xC000 {
+		t.Errorf("OptionalHeader() = %+v, %v", h, ok)
+	}
+
+	if names := f.SectionNames(); len(names) != 4 || names[3] != ".TI.ramfunc" {
+		t.Errorf("SectionNames() = %q", names)
+	}
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{".text", []byte{0x31, 0x40, 0x00, 0x04, 0x0C, 0x43, 0x30, 0x41}},
+		{".data", []byte{0x2A, 0x00, 0x00, 0x00}},
+		{".bss", nil},
+		{".TI.ramfunc", []byte{0x30, 0x41}},
+	} {
+		data, err := f.SectionData(tt.name)
+		if err != nil || !bytes.Equal(data, tt.data) {
+			t.Errorf("SectionData(%q) = % x, %v, want % x", tt.name, data, err, tt.data)
+		}
+	}
+	if stats := f.Stats(); stats.NumLineNumbers != 2 || stats.NumRelocations != 0 {
+		t.Errorf("Stats() = %+v, want 2 line numbers and no relocations", stats)
+	}
+
+	symbols, err := f.Symbols()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 7 {
+		t.Fatalf("got %d symbols, want 7", len(symbols))
+	}
+	if s := symbols[0]; s.Name != ".file" || s.StorageClass != C_FILE || s.SectionNumber != -2 || s.AuxiliaryEntry == nil {
+		t.Errorf(".file symbol = %+v", s)
+	}
+
+	text, err := f.Symbol(".text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aux := text.AuxiliaryEntry; aux == nil || aux.Size != 8 || aux.NumOfLineNumberEntries != 2 {
+		t.Errorf(".text auxiliary entry = %+v", aux)
+	}
+
+	main, err := f.Symbol("_main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main.Value != 0xC000 || main.BaseType() != T_INT || main.DerivedType() != DT_FCN || main.AuxiliaryEntry != nil {
+		t.Errorf("_main = %+v", main)
+	}
+	if s, err := f.Symbol("_counter_value"); err != nil || s.Value != 0x0200 || s.SectionNumber != 2 {
+		t.Errorf("Symbol(_counter_value) = %+v, %v", s, err)
+	}
+	if abs := f.AbsoluteSymbols(); len(abs) != 1 || abs[0].Name != "__STACK_SIZE" || abs[0].Value != 0x50 {
+		t.Errorf("AbsoluteSymbols() = %+v", abs)
+	}
+}
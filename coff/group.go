@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"runtime"
+	"sync"
+)
+
+// A SectionGroup reads the data of several sections concurrently. The zero
+// value is an empty group ready to use. Sections without raw data, such as
+// .bss, can be added but are not read.
+//
+// It is only a batch of reads. COFF has no equivalent of the ELF section
+// groups described by ELFGroup and SHTGroup in package debug.
+type SectionGroup struct {
+	sections []*Section
+}
+
+// Add adds a section to the group.
+func (g *SectionGroup) Add(s *Section) {
+	g.sections = append(g.sections, s)
+}
+
+// ReadAll reads the data of every section in the group, with at most
+// runtime.NumCPU() reads in flight at once. The data is returned in the order
+// the sections were added, sections without raw data such as .bss have nil
+// data. If any read fails, the first error encountered is returned.
+func (g *SectionGroup) ReadAll() ([][]byte, error) {
+	var (
+		data = make([][]byte, len(g.sections))
+		sem  = make(chan struct{}, runtime.NumCPU())
+		wg   sync.WaitGroup
+		once sync.Once
+		err  error
+	)
+
+	for i, s := range g.sections {
+		if !s.hasRawData() {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s *Section) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			bs := make([]byte, s.ByteSize())
+			if _, rerr := s.sr.ReadAt(bs, 0); rerr != nil {
+				once.Do(func() { err = rerr })
+				return
+			}
+			data[i] = bs
+		}(i, s)
+	}
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSectionGroupReadAll(t *testing.T) {
+	f := testFile(t)
+
+	var g SectionGroup
+	for _, s := range f.Sections {
+		g.Add(s)
+	}
+	data, err := g.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]byte{{1, 2, 3, 4, 5, 6, 7, 8}, {0xAA, 0xBB, 0xCC, 0xDD}, nil}
+	if len(data) != len(want) {
+		t.Fatalf("ReadAll returned %d sections, want %d", len(data), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(data[i], want[i]) || (want[i] == nil) != (data[i] == nil) {
+			t.Errorf("section %s: data %x, want %x", f.Sections[i].Name, data[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// HashSections hashes the raw data of every section with raw data
+// concurrently, using a new hash from h for each section, e.g. sha256.New. The
+// result maps section names to hash sums, when several sections share a name
+// the last one in file order wins. The errors of all failed sections are
+// joined and returned.
+func (f *File) HashSections(h func() hash.Hash) (map[string][]byte, error) {
+	var (
+		sums = make([][]byte, len(f.Sections))
+		errs = make([]error, len(f.Sections))
+		sem  = make(chan struct{}, runtime.NumCPU())
+		wg   sync.WaitGroup
+	)
+
+	for i, s := range f.Sections {
+		if !s.hasRawData() {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, s *Section) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			hh := h()
+			if _, err := io.Copy(hh, io.NewSectionReader(s.sr, 0, s.ByteSize())); err != nil {
+				errs[i] = fmt.Errorf("section %s: %w", s.Name, err)
+				return
+			}
+			sums[i] = hh.Sum(nil)
+		}(i, s)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string][]byte, len(f.Sections))
+	for i, s := range f.Sections {
+		if sums[i] != nil {
+			hashes[s.Name] = sums[i]
+		}
+	}
+	return hashes, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// fileJSON is the JSON representation of a File's metadata.
+type fileJSON struct {
+	FileHeader         FileHeader
+	OptionalFileHeader *OptionalFileHeader `json:",omitempty"`
+	Sections           []sectionJSON
+	Symbols            []Symbol
+}
+
+// sectionJSON is the JSON representation of a section header and its
+// relocation entries.
+type sectionJSON struct {
+	SectionHeader
+	RelocationEntries []RelocationEntry `json:",omitempty"`
+}
+
+// JSON returns the file header, optional file header, section headers,
+// relocation entries and symbols encoded as JSON. Raw section data is not
+// included.
+func (f *File) JSON() ([]byte, error) {
+	v := fileJSON{
+		FileHeader:         f.FileHeader,
+		OptionalFileHeader: f.OptionalFileHeader,
+		Sections:           make([]sectionJSON, len(f.Sections)),
+		Symbols:            f.symbols,
+	}
+	for i, s := range f.Sections {
+		v.Sections[i] = sectionJSON{s.SectionHeader, s.RelocationEntries}
+	}
+	return json.Marshal(v)
+}
+
+// WriteJSON writes the JSON encoding of f, as returned by File.JSON, to w.
+func WriteJSON(f *File, w io.Writer) error {
+	b, err := f.JSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ReadJSON decodes a File from the JSON written by WriteJSON. The file does not
+// need to be closed.
+//
+// The JSON does not include raw section data, so the sections of the returned
+// file have none: SectionData returns nil and NewBuilder fails with
+// ErrNoSectionData, the file cannot be rebuilt. Their headers are kept as
+// encoded, including RawDataAddress.
+func ReadJSON(r io.Reader) (*File, error) {
+	var v fileJSON
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, err
+	}
+
+	f := &File{
+		FileHeader:         v.FileHeader,
+		OptionalFileHeader: v.OptionalFileHeader,
+		Sections:           make([]*Section, len(v.Sections)),
+		symbols:            v.Symbols,
+		symbolIndex:        make([]int, 0, v.FileHeader.NumSymbolTableEntries),
+	}
+
+	f.byteOrder = byteOrderOf(&f.FileHeader)
+	var buf bytes.Buffer
+	binary.Write(&buf, f.byteOrder, &f.FileHeader)
+	f.rawHeader = buf.Bytes()
+
+	for i, sj := range v.Sections {
+		s := &Section{
+			SectionHeader:     sj.SectionHeader,
+			sr:                io.NewSectionReader(bytes.NewReader(nil), 0, 0),
+			RelocationEntries: sj.RelocationEntries,
+			noData:            true,
+		}
+		s.ReaderAt = s.sr
+
+		// Names longer than 8 bytes were stored in the string table, whose
+		// layout is not encoded. Leaving the raw name zero still marks them
+		// as such, which is all Validate needs.
+		if len(s.Name) <= len(s.rawName) {
+			copy(s.rawName[:], s.Name)
+		}
+		f.Sections[i] = s
+	}
+
+	for i, sym := range f.symbols {
+		f.symbolIndex = append(f.symbolIndex, i)
+		if sym.AuxiliaryEntry != nil {
+			f.symbolIndex = append(f.symbolIndex, -1)
+		}
+	}
+
+	return f, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	b := testBuilder()
+	b.Sections[0].RelocationEntries = []RelocationEntry{
+		{VirtualAddress: 0x1004, SymbolTableIndex: 2, Type: 0x11},
+	}
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteJSON(f, &buf); err != nil {
+		t.Fatal(err)
+	}
+	g, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.FileHeader != f.FileHeader || *g.OptionalFileHeader != *f.OptionalFileHeader {
+		t.Errorf("headers %+v %+v, want %+v %+v", g.FileHeader, g.OptionalFileHeader, f.FileHeader, f.OptionalFileHeader)
+	}
+	for i, s := range f.Sections {
+		gs := g.Sections[i]
+		if gs.SectionHeader != s.SectionHeader || !reflect.DeepEqual(gs.RelocationEntries, s.RelocationEntries) {
+			t.Errorf("section %d: got %+v %+v, want %+v %+v", i, gs.SectionHeader, gs.RelocationEntries, s.SectionHeader, s.RelocationEntries)
+		}
+	}
+	if !reflect.DeepEqual(g.symbols, f.symbols) {
+		t.Errorf("symbols %+v, want %+v", g.symbols, f.symbols)
+	}
+	if !bytes.Equal(g.RawHeader(), f.RawHeader()) {
+		t.Error("raw header differs")
+	}
+}
+
+func TestReadJSONHasNoSectionData(t *testing.T) {
+	b := testBuilder()
+	b.Sections = append(b.Sections, &BuilderSection{
+		SectionHeader: SectionHeader{Name: ".a_long_section_name", PhysicalAddress: 0x4000, VirtualAddress: 0x4000, Flags: STYP_DATA},
+		Data:          []byte{1, 2},
+	})
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = WriteJSON(f, &buf); err != nil {
+		t.Fatal(err)
+	}
+	g, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{".text", ".a_long_section_name"} {
+		if data, err := g.SectionData(name); data != nil || err != nil {
+			t.Errorf("SectionData(%s) = % x, %v, want nil, nil", name, data, err)
+		}
+	}
+	if warnings := g.Validate(); len(warnings) != 0 {
+		t.Errorf("Validate() = %v, want no warnings", warnings)
+	}
+	clone, err := g.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewBuilder(clone); !errors.Is(err, ErrNoSectionData) {
+		t.Errorf("NewBuilder of a clone error = %v, want ErrNoSectionData", err)
+	}
+	if _, err := NewBuilder(g); !errors.Is(err, ErrNoSectionData) {
+		t.Errorf("NewBuilder error = %v, want ErrNoSectionData", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"sort"
+)
+
+// An addrSymbol is an entry of the address-sorted symbol index.
+type addrSymbol struct {
+	addr  uint32
+	index int
+}
+
+// SymbolAt returns the symbol containing addr: the defined symbol with the
+// highest address not above addr, provided addr is within its size when the
+// symbol has one. Section symbols and absolute symbols are not considered.
+//
+// Symbol addresses are computed when SymbolAt is first called. C_STAT symbol
+// values that fall outside their section but within its size are treated as
+// section-relative and converted using the section's physical address. On
+// TMS470 the Thumb bit of symbols in text sections is cleared with PhysicalPC.
+func (f *File) SymbolAt(addr uint32) (Symbol, bool) {
+	f.symbolAtOnce.Do(f.buildAddrIndex)
+
+	i := sort.Search(len(f.addrIndex), func(i int) bool {
+		return f.addrIndex[i].addr > addr
+	})
+	if i == 0 {
+		return Symbol{}, false
+	}
+
+	entry := f.addrIndex[i-1]
+	sym := f.symbols[entry.index]
+	if sym.AuxiliaryEntry != nil && sym.AuxiliaryEntry.Size > 0 && addr-entry.addr >= sym.AuxiliaryEntry.Size {
+		return Symbol{}, false
+	}
+	return sym, true
+}
+
+func (f *File) buildAddrIndex() {
+	index := make([]addrSymbol, 0, len(f.symbols))
+	for i, sym := range f.symbols {
+		if sym.SectionNumber < 1 || int(sym.SectionNumber) > len(f.Sections) {
+			continue
+		}
+		section := f.Sections[sym.SectionNumber-1]
+		if sym.Name == section.Name || sym.StorageClass == C_FILE {
+			continue
+		}
+
+		addr := sym.Value
+		inSection := addr >= section.PhysicalAddress && addr-section.PhysicalAddress < section.Size
+		if sym.StorageClass == C_STAT && !inSection && addr < section.Size {
+			addr += section.PhysicalAddress
+		}
+		if section.Flags&STYP_TEXT != 0 {
+			addr = f.TargetID.PhysicalPC(addr)
+		}
+
+		index = append(index, addrSymbol{addr, i})
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[i].addr < index[j].addr
+	})
+	f.addrIndex = index
+}
+
+// FindSymbolByValue returns the defined symbol with the highest value not
+// above value. When several symbols share that value, the one with the
+// largest section number is returned. Unlike SymbolAt, the raw symbol values
+// are compared and absolute symbols are considered.
+//
+// The symbols are sorted by value on the first call, which takes O(N log N)
+// time, later calls take O(log N) time.
+func (f *File) FindSymbolByValue(value uint32) (Symbol, bool) {
+	f.valueIndexOnce.Do(f.buildValueIndex)
+
+	i := sort.Search(len(f.valueIndex), func(i int) bool {
+		return f.symbols[f.valueIndex[i]].Value > value
+	})
+	if i == 0 {
+		return Symbol{}, false
+	}
+	return f.symbols[f.valueIndex[i-1]], true
+}
+
+func (f *File) buildValueIndex() {
+	index := make([]int, 0, len(f.symbols))
+	for i, sym := range f.symbols {
+		if sym.SectionNumber != 0 && sym.StorageClass != C_FILE {
+			index = append(index, i)
+		}
+	}
+
+	sort.SliceStable(index, func(i, j int) bool {
+		a, b := f.symbols[index[i]], f.symbols[index[j]]
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.SectionNumber < b.SectionNumber
+	})
+	f.valueIndex = index
+}
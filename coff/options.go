@@ -0,0 +1,104 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+// progressInterval is the number of entries between progress callbacks.
+const progressInterval = 100
+
+// defaultMaxStringTableSize is the default limit of the string table size.
+const defaultMaxStringTableSize = 64 << 20
+
+// An Option configures how NewFileOpts parses a file.
+type Option func(*options)
+
+type options struct {
+	byteOrder   binary.ByteOrder
+	strict      bool
+	maxSections int
+
+	maxStringTableSize int64
+	progress           func(phase string, done, total int)
+	ctx                context.Context
+}
+
+// WithByteOrder sets the byte order of the file headers, symbol table and
+// relocation entries. By default it is detected from the file header, files
+// whose target ID is byte-swapped are big-endian and all others
+// little-endian.
+func WithByteOrder(bo binary.ByteOrder) Option {
+	return func(o *options) {
+		o.byteOrder = bo
+	}
+}
+
+// WithStrictValidation enables checks that reject files which can otherwise be
+// parsed, such as sections whose raw data or relocation entries extend past
+// the end of the file and section headers with reserved fields that are not
+// zero.
+func WithStrictValidation(strict bool) Option {
+	return func(o *options) {
+		o.strict = strict
+	}
+}
+
+// WithMaxSections rejects files with more than n sections with
+// ErrTooManySections, protecting against corrupt section counts. A limit of
+// zero or less disables the check.
+func WithMaxSections(n int) Option {
+	return func(o *options) {
+		o.maxSections = n
+	}
+}
+
+// WithProgressCallback sets fn to be called while the section headers and
+// symbol table are read. The phase is either "sections" or "symbols", fn is
+// called every 100 entries and once more when the phase is complete.
+func WithProgressCallback(fn func(phase string, done, total int)) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// WithMaxStringTableSize rejects files whose string table is larger than n
+// bytes with ErrStringTableTooLarge. The string table extends to the end of
+// the file, so a corrupt symbol table address could otherwise cause the whole
+// file to be read into memory. The default limit is 64 MiB.
+func WithMaxStringTableSize(n int64) Option {
+	return func(o *options) {
+		o.maxStringTableSize = n
+	}
+}
+
+// withContext sets the context checked while parsing, see NewFileCtx.
+func withContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// checkContext returns the context error, if any, annotated with the parse
+// position.
+func (o *options) checkContext(position string) error {
+	if o.ctx == nil {
+		return nil
+	}
+	if err := o.ctx.Err(); err != nil {
+		return fmt.Errorf("parse cancelled %s: %w", position, err)
+	}
+	return nil
+}
+
+// reportProgress calls the progress callback if moving from prev to done
+// entries crossed a progress interval or completed the phase.
+func (o *options) reportProgress(phase string, prev, done, total int) {
+	if o.progress != nil && (prev/progressInterval != done/progressInterval || done == total) {
+		o.progress(phase, done, total)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A RelocationEntry represents a COFF section relocation entry.
+type RelocationEntry struct {
+	// VirtualAddress is the address of the field to patch, in the section's
+	// address space before relocation.
+	VirtualAddress uint32
+
+	// SymbolTableIndex is the index of the referenced symbol in the symbol
+	// table, see File.SymbolByIndex. An index of InternalRelocation means the
+	// reference is relative to the section itself.
+	SymbolTableIndex uint32
+
+	// ExtendedAddress is only used by the 12-byte format of the C5400 and
+	// C5500, it is zero for all other targets.
+	ExtendedAddress uint16
+
+	Type uint16
+}
+
+// InternalRelocation is the symbol table index of a relocation which is
+// relative to the section being relocated rather than to a symbol.
+const InternalRelocation uint32 = 0xFFFF
+
+// IsInternal reports whether the relocation is relative to the section being
+// relocated rather than to a symbol.
+func (r *RelocationEntry) IsInternal() bool {
+	return r.SymbolTableIndex == InternalRelocation || r.SymbolTableIndex == 0xFFFFFFFF
+}
+
+// relocationEntry10 is the 10-byte relocation entry used by the C2800, C6000,
+// MSP430 and TMS470.
+type relocationEntry10 struct {
+	VirtualAddress   uint32
+	SymbolTableIndex uint16
+	_                uint16
+	Type             uint16
+}
+
+// relocationEntry12 is the 12-byte relocation entry used by the C5400 and
+// C5500.
+type relocationEntry12 struct {
+	VirtualAddress   uint32
+	SymbolTableIndex uint32
+	ExtendedAddress  uint16
+	Type             uint16
+}
+
+// usesLongRelocationEntries checks if the target uses the 12-byte relocation
+// entry format.
+func usesLongRelocationEntries(tid TargetID) bool {
+	switch tid {
+	case 0x0098, 0x009C, 0x00A1:
+		return true
+	}
+	return false
+}
+
+func readRelocationEntries(r io.ReaderAt, header *SectionHeader, tid TargetID, bo binary.ByteOrder) (relocs []RelocationEntry, err error) {
+	if header.NumRelocationEntries == 0 {
+		return
+	}
+
+	long := usesLongRelocationEntries(tid)
+	size := int64(binary.Size(relocationEntry10{}))
+	if long {
+		size = int64(binary.Size(relocationEntry12{}))
+	}
+
+	// Check that the entries are present before allocating for them, the
+	// count comes straight from the section header
+	total := int64(header.NumRelocationEntries) * size
+	var b [1]byte
+	if _, err = r.ReadAt(b[:], int64(header.RelocationEntriesAddress)+total-1); err != nil {
+		return nil, fmt.Errorf("%d entries at offset %d: %w", header.NumRelocationEntries, header.RelocationEntriesAddress, ErrRelocationsOutOfBounds)
+	}
+
+	sr := io.NewSectionReader(r, int64(header.RelocationEntriesAddress), total)
+	relocs = make([]RelocationEntry, header.NumRelocationEntries)
+	for i := range relocs {
+		if long {
+			var entry relocationEntry12
+			err = binary.Read(sr, bo, &entry)
+			if err != nil {
+				return nil, err
+			}
+			relocs[i] = RelocationEntry{
+				VirtualAddress:   entry.VirtualAddress,
+				SymbolTableIndex: entry.SymbolTableIndex,
+				ExtendedAddress:  entry.ExtendedAddress,
+				Type:             entry.Type,
+			}
+		} else {
+			var entry relocationEntry10
+			err = binary.Read(sr, bo, &entry)
+			if err != nil {
+				return nil, err
+			}
+			relocs[i] = RelocationEntry{
+				VirtualAddress:   entry.VirtualAddress,
+				SymbolTableIndex: uint32(entry.SymbolTableIndex),
+				Type:             entry.Type,
+			}
+		}
+	}
+	return
+}
+
+// genericRelocationTypes are the relocation types shared by all targets, used
+// to encode relocation expressions.
+var genericRelocationTypes = map[uint16]string{
+	0x4000: "RE_ADD",
+	0x4001: "RE_SUB",
+	0x4002: "RE_NEG",
+	0x4003: "RE_MPY",
+	0x4004: "RE_DIV",
+	0x4005: "RE_MOD",
+	0x4006: "RE_SR",
+	0x4007: "RE_ASR",
+	0x4008: "RE_SL",
+	0x4009: "RE_AND",
+	0x400A: "RE_OR",
+	0x400B: "RE_XOR",
+	0x400C: "RE_NOTB",
+	0x400D: "RE_ULDFLD",
+	0x400E: "RE_SLDFLD",
+	0x400F: "RE_USTFLD",
+	0x4010: "RE_SSTFLD",
+	0x4011: "RE_PUSH",
+	0x4012: "RE_PUSHSK",
+	0x4013: "RE_PUSHUK",
+	0x4014: "RE_PUSHPC",
+	0x4015: "RE_DUP",
+	0x4016: "RE_XSTFLD",
+	0xC011: "RE_PUSHSV",
+}
+
+var (
+	c6000RelocationTypes = map[uint16]string{
+		0x0000: "R_ABS",
+		0x000F: "R_RELBYTE",
+		0x0010: "R_RELWORD",
+		0x0011: "R_RELLONG",
+		0x0050: "R_C60BASE",
+		0x0051: "R_C60DIR15",
+		0x0052: "R_C60PCR21",
+		0x0053: "R_C60PCR10",
+		0x0054: "R_C60LO16",
+		0x0055: "R_C60HI16",
+		0x0056: "R_C60SECT",
+		0x0057: "R_C60S16",
+		0x0070: "R_C60PCR7",
+		0x0071: "R_C60PCR12",
+	}
+
+	c2800RelocationTypes = map[uint16]string{
+		0x0000: "R_ABS",
+		0x000F: "R_RELBYTE",
+		0x0010: "R_RELWORD",
+		0x0011: "R_RELLONG",
+		0x0028: "R_PARTLS7",
+		0x005D: "R_PARTLS6",
+		0x005E: "R_PARTMID10",
+		0x005F: "R_REL22",
+		0x0060: "R_PARTMS6",
+		0x0061: "R_PARTS16",
+		0x0062: "R_C28PCR16",
+		0x0063: "R_C28PCR8",
+		0x0064: "R_C28PTR",
+		0x0065: "R_C28HI16",
+		0x0066: "R_C28LOPTR",
+		0x0067: "R_C28NWORD",
+		0x0068: "R_C28NBYTE",
+		0x0069: "R_C28HIBYTE",
+		0x006A: "R_C28RELS13",
+	}
+
+	c5400RelocationTypes = map[uint16]string{
+		0x0000: "R_ABS",
+		0x0005: "R_REL24",
+		0x0017: "R_RELBYTE",
+		0x0020: "R_RELWORD",
+		0x0021: "R_RELLONG",
+		0x0028: "R_PARTLS7",
+		0x0029: "R_PARTMS9",
+		0x002A: "R_REL13",
+	}
+
+	c5500RelocationTypes = map[uint16]string{
+		0x0000: "R_ABS",
+		0x0005: "R_REL24",
+		0x0017: "R_RELBYTE",
+		0x0020: "R_RELWORD",
+		0x0021: "R_RELLONG",
+		0x0170: "R_LD3_DMA",
+		0x0172: "R_LD3_MDP",
+		0x0173: "R_LD3_PDP",
+		0x0174: "R_LD3_REL23",
+		0x0210: "R_LD3_k8",
+		0x0211: "R_LD3_k16",
+		0x0212: "R_LD3_K8",
+		0x0213: "R_LD3_K16",
+		0x0214: "R_LD3_I8",
+		0x0215: "R_LD3_I16",
+		0x0216: "R_LD3_L8",
+		0x0217: "R_LD3_L16",
+		0x0220: "R_LD3_k4",
+		0x0221: "R_LD3_k5",
+		0x0222: "R_LD3_K5",
+		0x0223: "R_LD3_k6",
+		0x0224: "R_LD3_k12",
+	}
+
+	msp430TMS470RelocationTypes = map[uint16]string{
+		0x0011: "R_RELLONG",
+		0x0016: "R_PCR23H",
+		0x0017: "R_PCR24W",
+	}
+)
+
+// relocationTypes maps target IDs to their relocation type names, as listed in
+// the TI-COFF specification.
+var relocationTypes = map[TargetID]map[uint16]string{
+	0x0097: msp430TMS470RelocationTypes,
+	0x0098: c5400RelocationTypes,
+	0x0099: c6000RelocationTypes,
+	0x009C: c5500RelocationTypes,
+	0x009D: c2800RelocationTypes,
+	0x00A0: msp430TMS470RelocationTypes,
+	0x00A1: c5500RelocationTypes,
+}
+
+// TypeString returns the mnemonic of the relocation type on the target, such
+// as "R_RELLONG", or "Unknown(N)" if the type is not defined for the target.
+func (r *RelocationEntry) TypeString(tid TargetID) string {
+	if name, ok := relocationTypes[tid][r.Type]; ok {
+		return name
+	}
+	if name, ok := genericRelocationTypes[r.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", r.Type)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestCorruptRelocationCount(t *testing.T) {
+	b := testBuilder()
+	b.Sections[0].RelocationEntries = []RelocationEntry{
+		{VirtualAddress: 0x1004, SymbolTableIndex: 2, Type: 0x11},
+	}
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NumRelocationEntries of the first section header
+	off := CoffFileHeaderSize + binary.Size(OptionalFileHeader{}) + 8 + 24
+	binary.LittleEndian.PutUint32(data[off:], 0xFFFFFFFF)
+
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewFile: %v", err)
+	}
+	if relocs, err := f.Sections[0].Relocations(); relocs != nil || !errors.Is(err, ErrRelocationsOutOfBounds) {
+		t.Errorf("Relocations() = %v, %v, want nil, ErrRelocationsOutOfBounds", relocs, err)
+	}
+	if _, err = NewBuilder(f); !errors.Is(err, ErrRelocationsOutOfBounds) {
+		t.Errorf("NewBuilder error = %v, want ErrRelocationsOutOfBounds", err)
+	}
+
+	if _, err = NewFileOpts(bytes.NewReader(data), WithStrictValidation(true)); !errors.Is(err, ErrRelocationsOutOfBounds) {
+		t.Errorf("strict NewFileOpts error = %v, want ErrRelocationsOutOfBounds", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// ParseDebugSrcPath returns the source file search paths stored in a
+// .debug_srcpath section. The section holds a sequence of null-terminated
+// strings, ended by an empty string or the end of the section.
+func ParseDebugSrcPath(sec *Section) (paths []string, err error) {
+	data, err := ioutil.ReadAll(io.NewSectionReader(sec, 0, sec.ByteSize()))
+	if err != nil {
+		return
+	}
+
+	for off := 0; off < len(data); {
+		i := bytes.IndexByte(data[off:], 0)
+		if i < 0 {
+			return nil, fmt.Errorf("section %s: path at offset %d is not terminated", sec.Name, off)
+		}
+		if i == 0 {
+			break
+		}
+		paths = append(paths, string(data[off:off+i]))
+		off += i + 1
+	}
+	return
+}
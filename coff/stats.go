@@ -0,0 +1,53 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import "encoding/binary"
+
+// COFFStats summarizes the contents of a COFF file.
+type COFFStats struct {
+	NumSections     uint64
+	NumSymbols      uint64 // Not counting auxiliary entries
+	NumRelocations  uint64
+	NumLineNumbers  uint64 // Only recorded on TMS470 and MSP430
+	StringTableSize uint64
+
+	// TotalFileSize is the size of the file. If the file has no string
+	// table it is the end of the last section data or relocation entries
+	// instead, which excludes any trailing data.
+	TotalFileSize uint64
+}
+
+// Stats returns statistics of the file, computed from the parsed headers.
+func (f *File) Stats() COFFStats {
+	stats := COFFStats{
+		NumSections:     uint64(len(f.Sections)),
+		NumSymbols:      uint64(len(f.symbols)),
+		StringTableSize: f.stringTableSize,
+		TotalFileSize:   f.fileSize,
+	}
+
+	end := uint64(len(f.rawHeader)) + uint64(f.OptionalFileHeaderSize) + uint64(len(f.Sections))*CoffSectionHeaderSize
+	relocSize := uint64(binary.Size(relocationEntry10{}))
+	if usesLongRelocationEntries(f.TargetID) {
+		relocSize = uint64(binary.Size(relocationEntry12{}))
+	}
+	for _, s := range f.Sections {
+		stats.NumRelocations += uint64(s.NumRelocationEntries)
+		stats.NumLineNumbers += uint64(s.numLineNumbers)
+
+		if dataEnd := uint64(s.RawDataAddress) + uint64(s.ByteSize()); s.hasRawData() && dataEnd > end {
+			end = dataEnd
+		}
+		if s.NumRelocationEntries > 0 {
+			if relocEnd := uint64(s.RelocationEntriesAddress) + uint64(s.NumRelocationEntries)*relocSize; relocEnd > end {
+				end = relocEnd
+			}
+		}
+	}
+	if stats.TotalFileSize == 0 {
+		stats.TotalFileSize = end
+	}
+	return stats
+}
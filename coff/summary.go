@@ -0,0 +1,59 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Summary returns a human-readable report of the file, laid out like the file
+// header and section table listings of TI's ofd utility.
+func (f *File) Summary() string {
+	var buf bytes.Buffer
+
+	var numExternal int
+	for _, sym := range f.symbols {
+		if sym.StorageClass == C_EXT {
+			numExternal++
+		}
+	}
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "File Header Information\n")
+	fmt.Fprintf(w, "\tTarget ID:\t%v\n", f.TargetID)
+	fmt.Fprintf(w, "\tCOFF Version:\t%d\n", f.COFFVersion())
+	fmt.Fprintf(w, "\tFlags:\t0x%04X\n", f.Flags)
+	if f.OptionalFileHeader != nil {
+		fmt.Fprintf(w, "\tEntry Point:\t0x%08X\n", f.OptionalFileHeader.EntryPoint)
+	} else {
+		fmt.Fprintf(w, "\tEntry Point:\tnone\n")
+	}
+	fmt.Fprintf(w, "\tSections:\t%d\n", len(f.Sections))
+	fmt.Fprintf(w, "\tSymbols:\t%d\n", len(f.symbols))
+	fmt.Fprintf(w, "\tExternal Symbols:\t%d\n", numExternal)
+	fmt.Fprintf(w, "\nSection Information\n")
+	fmt.Fprintf(w, "\tName\tAddress\tSize\tFlags\n")
+	for _, s := range f.Sections {
+		fmt.Fprintf(w, "\t%s\t0x%08X\t0x%08X\t0x%08X\n", s.Name, s.PhysicalAddress, s.Size, uint32(s.Flags))
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+// DumpSectionHeaders writes a table of all section headers to w, like the
+// section listing of TI's ofd -h.
+func (f *File) DumpSectionHeaders(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Idx\tName\tPhys Addr\tVirt Addr\tSize\tFlags\tPage\n")
+	fmt.Fprintf(tw, "---\t----\t---------\t---------\t----\t-----\t----\n")
+	for i, s := range f.Sections {
+		fmt.Fprintf(tw, "%d\t%s\t0x%08X\t0x%08X\t0x%08X\t0x%08X\t%d\n",
+			i+1, s.Name, s.PhysicalAddress, s.VirtualAddress, s.Size, uint32(s.Flags), s.MemoryPageNumber)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import "sort"
+
+// A SymbolTable is a list of symbols, as returned by File.SymbolTable.
+//
+// A symbol only records the number of its section, not the section flags, so
+// the methods that distinguish text from data symbols take the sections the
+// section numbers refer to.
+type SymbolTable []Symbol
+
+// TotalSize returns the sum of the sizes recorded in the auxiliary entries of
+// the symbols.
+func (t SymbolTable) TotalSize() uint64 {
+	var size uint64
+	for _, sym := range t {
+		if sym.AuxiliaryEntry != nil {
+			size += uint64(sym.AuxiliaryEntry.Size)
+		}
+	}
+	return size
+}
+
+// TextSize returns the sum of the sizes of the C_FCN symbols in text
+// sections. The section numbers of the symbols refer to sections, which is
+// normally File.Sections.
+func (t SymbolTable) TextSize(sections []*Section) uint64 {
+	return t.sizeOf(sections, C_FCN, STYP_TEXT)
+}
+
+// DataSize returns the sum of the sizes of the C_STAT symbols in data
+// sections, not counting the section symbols, whose size is that of the whole
+// section. The section numbers of the symbols refer to sections, which is
+// normally File.Sections.
+func (t SymbolTable) DataSize(sections []*Section) uint64 {
+	return t.sizeOf(sections, C_STAT, STYP_DATA)
+}
+
+// GroupByStorageClass returns the symbols grouped by storage class, each group
+// in symbol table order. File.GroupByStorageClass caches the grouping of the
+// file's symbols.
+func (t SymbolTable) GroupByStorageClass() map[StorageClass][]Symbol {
+	groups := make(map[StorageClass][]Symbol)
+	for _, sym := range t {
+		groups[sym.StorageClass] = append(groups[sym.StorageClass], sym)
+	}
+	return groups
+}
+
+// GroupByStorageClass is like SymbolTable.GroupByStorageClass for the symbols
+// of the file. The map is built on the first call and shared between calls,
+// it must not be modified.
+func (f *File) GroupByStorageClass() map[StorageClass][]Symbol {
+	f.storageClassOnce.Do(func() {
+		f.storageClassGroups = SymbolTable(f.symbols).GroupByStorageClass()
+	})
+	return f.storageClassGroups
+}
+
+// AllSymbolsBySection returns the symbols defined in sections, keyed by
+// section name and sorted by value within each section. Undefined, absolute
+// and debugging symbols are left out.
+func (f *File) AllSymbolsBySection() map[string][]Symbol {
+	bySection := make(map[string][]Symbol)
+	for _, sym := range f.symbols {
+		if sym.SectionNumber < 1 || int(sym.SectionNumber) > len(f.Sections) {
+			continue
+		}
+		name := f.Sections[sym.SectionNumber-1].Name
+		bySection[name] = append(bySection[name], sym)
+	}
+	for _, symbols := range bySection {
+		sort.SliceStable(symbols, func(i, j int) bool {
+			return symbols[i].Value < symbols[j].Value
+		})
+	}
+	return bySection
+}
+
+func (t SymbolTable) sizeOf(sections []*Section, class StorageClass, flags SectionHeaderFlags) uint64 {
+	var size uint64
+	for _, sym := range t {
+		if sym.AuxiliaryEntry == nil || sym.StorageClass != class {
+			continue
+		}
+		if sym.SectionNumber < 1 || int(sym.SectionNumber) > len(sections) {
+			continue
+		}
+		section := sections[sym.SectionNumber-1]
+		if section.Flags&flags != 0 && sym.Name != section.Name {
+			size += uint64(sym.AuxiliaryEntry.Size)
+		}
+	}
+	return size
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+//go:build ignore
+
+// mkmsp430 writes msp430.out, a linked MSP430 executable laid out by hand
+// after SPRAAO8 the way cl430 lays out its output: a .file symbol, section
+// symbols with auxiliary entries, line numbers for .text, a long section
+// name and a long symbol name in the string table. It deliberately does not
+// use the coff package, so the fixture does not share its bugs.
+//
+// Run it from this directory with: go run mkmsp430.go
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"log"
+)
+
+type fileHeader struct {
+	Version                 uint16
+	NumSections             uint16
+	Timestamp               int32
+	SymbolTableStartAddress int32
+	NumSymbolTableEntries   int32
+	OptionalFileHeaderSize  uint16
+	Flags                   uint16
+	TargetID                uint16
+}
+
+type optionalHeader struct {
+	Magic     uint16
+	Version   uint16
+	TextSize  int32
+	DataSize  int32
+	BSSSize   int32
+	Entry     int32
+	TextStart int32
+	DataStart int32
+}
+
+type sectionHeader struct {
+	Name         [8]byte
+	PAddr, VAddr uint32
+	Size         uint32
+	RawData      uint32
+	Relocations  uint32
+	LineNumbers  uint32
+	NumRelocs    uint32
+	NumLines     uint32
+	Flags        uint32
+	Reserved     uint16
+	Page         uint16
+}
+
+type symbol struct {
+	Name          [8]byte
+	Value         uint32
+	SectionNumber int16
+	Type          uint16
+	StorageClass  uint8
+	NumAux        uint8
+}
+
+type sectionAux struct {
+	Size      uint32
+	NumRelocs uint16
+	NumLines  uint16
+	_         [10]byte
+}
+
+type lineNumber struct {
+	Addr uint32 // Symbol index when Line is 0
+	Line uint16
+}
+
+// strtab accumulates the string table, starting with its size field.
+var strtab = []byte{0, 0, 0, 0}
+
+func name(s string) (n [8]byte) {
+	if len(s) <= 8 {
+		copy(n[:], s)
+		return
+	}
+	binary.LittleEndian.PutUint32(n[4:], uint32(len(strtab)))
+	strtab = append(append(strtab, s...), 0)
+	return
+}
+
+func main() {
+	text := []byte{0x31, 0x40, 0x00, 0x04, 0x0C, 0x43, 0x30, 0x41} // mov #0x400,SP; clr r12; ret
+	data := []byte{0x2A, 0x00, 0x00, 0x00}
+	ramfunc := []byte{0x30, 0x41} // ret
+	lines := []lineNumber{{Addr: 8, Line: 0}, {Addr: 0xC004, Line: 3}}
+
+	const (
+		headersEnd = 22 + 28 + 4*48
+		dataOff    = headersEnd + 8
+		ramfuncOff = dataOff + 4
+		linesOff   = ramfuncOff + 2
+		symtabOff  = linesOff + 2*6
+		numSymbols = 11
+	)
+
+	sections := []sectionHeader{
+		{Name: name(".text"), PAddr: 0xC000, VAddr: 0xC000, Size: 8, RawData: headersEnd, LineNumbers: linesOff, NumLines: 2, Flags: 0x20},
+		{Name: name(".data"), PAddr: 0x0200, VAddr: 0x0200, Size: 4, RawData: dataOff, Flags: 0x40},
+		{Name: name(".bss"), PAddr: 0x0204, VAddr: 0x0204, Size: 6, Flags: 0x80},
+		{Name: name(".TI.ramfunc"), PAddr: 0x1C00, VAddr: 0x1C00, Size: 2, RawData: ramfuncOff, Flags: 0x20},
+	}
+
+	var file [8]byte
+	copy(file[:], "main.c")
+	symbols := []interface{}{
+		symbol{Name: name(".file"), SectionNumber: -2, StorageClass: 103, NumAux: 1},
+		sectionAux{Size: binary.LittleEndian.Uint32(file[:4]), NumRelocs: binary.LittleEndian.Uint16(file[4:6])},
+		symbol{Name: name(".text"), Value: 0xC000, SectionNumber: 1, StorageClass: 3, NumAux: 1},
+		sectionAux{Size: 8, NumLines: 2},
+		symbol{Name: name(".data"), Value: 0x0200, SectionNumber: 2, StorageClass: 3, NumAux: 1},
+		sectionAux{Size: 4},
+		symbol{Name: name(".bss"), Value: 0x0204, SectionNumber: 3, StorageClass: 3, NumAux: 1},
+		sectionAux{Size: 6},
+		symbol{Name: name("_main"), Value: 0xC000, SectionNumber: 1, Type: 0x24, StorageClass: 2},
+		symbol{Name: name("_counter_value"), Value: 0x0200, SectionNumber: 2, Type: 0x04, StorageClass: 2},
+		symbol{Name: name("__STACK_SIZE"), Value: 0x50, SectionNumber: -1, StorageClass: 2},
+	}
+	binary.LittleEndian.PutUint32(strtab, uint32(len(strtab)))
+
+	var buf bytes.Buffer
+	w := func(v interface{}) {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			log.Fatal(err)
+		}
+	}
+	w(fileHeader{
+		Version:                 0xC2,
+		NumSections:             uint16(len(sections)),
+		SymbolTableStartAddress: symtabOff,
+		NumSymbolTableEntries:   numSymbols,
+		OptionalFileHeaderSize:  28,
+		Flags:                   0x0103, // F_RELFLG | F_EXEC | F_LITTLE
+		TargetID:                0x00A0,
+	})
+	w(optionalHeader{Magic: 0x0108, TextSize: 10, DataSize: 4, BSSSize: 6, Entry: 0xC000, TextStart: 0xC000, DataStart: 0x0200})
+	w(sections)
+	w(text)
+	w(data)
+	w(ramfunc)
+	w(lines)
+	for _, s := range symbols {
+		w(s)
+	}
+	w(strtab)
+
+	if buf.Len() != symtabOff+numSymbols*18+len(strtab) {
+		log.Fatalf("layout mismatch: %d bytes", buf.Len())
+	}
+	if err := ioutil.WriteFile("msp430.out", buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package coff
+
+import "fmt"
+
+// A Severity grades a validation warning.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	}
+	return fmt.Sprintf("Severity%d", int(s))
+}
+
+// A Warning describes a problem in a file that does not prevent it from being
+// parsed.
+type Warning struct {
+	Description string
+	Severity    Severity
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Severity, w.Description)
+}
+
+// Validate checks the file for problems that NewFile tolerates and returns a
+// warning for each of them. Errors that prevent parsing are reported by
+// NewFile instead.
+func (f *File) Validate() []Warning {
+	var warnings []Warning
+	for i, s := range f.Sections {
+		warnings = append(warnings, validateSectionName(i+1, s.rawName)...)
+	}
+	return warnings
+}
+
+// validateSectionName checks that an inline section name is printable ASCII
+// padded with trailing nulls. Names stored in the string table are not
+// checked.
+func validateSectionName(index int, name [8]byte) []Warning {
+	if name[0] == 0 && name[1] == 0 && name[2] == 0 && name[3] == 0 {
+		return nil
+	}
+
+	padding := false
+	for _, c := range name {
+		var problem string
+		switch {
+		case c == 0:
+			padding = true
+		case padding:
+			problem = "an embedded null"
+		case c < 0x20 || c > 0x7E:
+			problem = fmt.Sprintf("non-printable character %#02x", c)
+		}
+		if problem != "" {
+			return []Warning{{
+				Description: fmt.Sprintf("section %d: name %q has %s", index, name[:], problem),
+				Severity:    SeverityWarning,
+			}}
+		}
+	}
+	return nil
+}
@@ -6,12 +6,41 @@
 package debug
 
 import (
+	"bufio"
 	"debug/elf"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/awarepoint/go-debug/coff"
+	"github.com/awarepoint/go-debug/internal/mmap"
+)
+
+var (
+	// ErrSectionNotFound is returned when a named section does not exist.
+	ErrSectionNotFound = errors.New("section not found")
+
+	// ErrSymbolNotFound is returned when a named symbol does not exist.
+	ErrSymbolNotFound = errors.New("symbol not found")
+
+	// ErrReadOnly is returned when modifying a file which is not backed by
+	// writable memory.
+	ErrReadOnly = errors.New("file is read-only")
+
+	// ErrSectionTruncated is returned when data extends past the end of
+	// a section.
+	ErrSectionTruncated = errors.New("section truncated")
+
+	// ErrOutOfRange is returned when a read starts outside of a section.
+	ErrOutOfRange = errors.New("read out of section range")
+
+	// ErrWrongFileType is returned by NewFileWithType when the file is not
+	// of the requested type.
+	ErrWrongFileType = errors.New("wrong file type")
 )
 
 type FileType int
@@ -42,73 +71,224 @@ type File struct {
 
 	Symbols []Symbol
 
+	// DynamicSymbols are the symbols of the ELF dynamic symbol table, which
+	// stripped shared libraries keep as their only symbols, with their
+	// versions. It is nil for COFF files.
+	DynamicSymbols []Symbol
+
+	// Exactly one of elf and coff is set, depending on FileType.
+	elf  *elf.File
+	coff *coff.File
+
+	// r is the reader the file was parsed from.
+	r io.ReaderAt
+
+	sectionIndexOnce sync.Once
+	sectionIndex     map[string]int
+
+	addrIndexOnce sync.Once
+	addrIndex     []AddressRange
+
+	// groups are the ELF section groups and groupMembership the signature
+	// of the group of each member section, by section index. They are
+	// read by loadGroups.
+	groupsOnce      sync.Once
+	groups          []ELFGroup
+	groupMembership map[int]string
+	groupsErr       error
+
 	closer io.Closer
 }
 
 // NewFile creates a new file for access
-func NewFile(r io.ReaderAt) (file *File, err error) {
-	file = new(File)
+func NewFile(r io.ReaderAt, opts ...Option) (file *File, err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	es := make(ErrorSlice, 0)
 
-	var ef *elf.File
-	ef, err = elf.NewFile(r)
-	if err == nil {
-		file.FileType = FileTypeELF
-
-		file.Sections = make([]Section, len(ef.Sections))
-		for i, section := range ef.Sections {
-			file.Sections[i] = &elfSection{section}
+	for _, t := range o.detectionOrder() {
+		switch t {
+		case FileTypeELF:
+			var ef *elf.File
+			ef, err = elf.NewFile(r)
+			if err != nil {
+				es = append(es, fmt.Errorf("debug/elf: %v", err))
+				continue
+			}
+			return newELFFile(ef, r)
+
+		case FileTypeCOFF:
+			var cf *coff.File
+			cf, err = coff.NewFile(r)
+			if err != nil {
+				es = append(es, fmt.Errorf("debug/coff: %v", err))
+				continue
+			}
+			return newCOFFFile(cf, r)
 		}
+	}
 
-		var symbols []elf.Symbol
-		symbols, err = ef.Symbols()
+	return nil, es
+}
+
+// NewFileWithType creates a new file for access, parsing r as type t without
+// trying other file types. ErrWrongFileType is returned if the magic number
+// of the file does not match t.
+func NewFileWithType(r io.ReaderAt, t FileType) (*File, error) {
+	switch t {
+	case FileTypeELF:
+		var magic [len(elf.ELFMAG)]byte
+		if _, err := r.ReadAt(magic[:], 0); err != nil || string(magic[:]) != elf.ELFMAG {
+			return nil, ErrWrongFileType
+		}
+		ef, err := elf.NewFile(r)
 		if err != nil {
-			return
+			return nil, fmt.Errorf("debug/elf: %v", err)
+		}
+		return newELFFile(ef, r)
+
+	case FileTypeCOFF:
+		cf, err := coff.NewFile(r)
+		if err == coff.ErrInvalidTargetID {
+			return nil, ErrWrongFileType
 		}
-		file.Symbols = make([]Symbol, len(symbols))
-		for i := 0; i < len(file.Symbols); i++ {
-			file.Symbols[i].Name = symbols[i].Name
-			file.Symbols[i].Value = symbols[i].Value
-			file.Symbols[i].Size = symbols[i].Size
+		if err != nil {
+			return nil, fmt.Errorf("debug/coff: %v", err)
 		}
+		return newCOFFFile(cf, r)
+	}
+	return nil, fmt.Errorf("unsupported file type %v", t)
+}
 
-		return file, nil
-	} else {
-		es = append(es, fmt.Errorf("debug/elf: %v", err))
+func newELFFile(ef *elf.File, r io.ReaderAt) (file *File, err error) {
+	file = new(File)
+	file.FileType = FileTypeELF
+	file.elf = ef
+	file.r = r
+
+	w := writerAt(r)
+	file.Sections = make([]Section, len(ef.Sections))
+	for i, section := range ef.Sections {
+		file.Sections[i] = &elfSection{section, w, file, i}
 	}
 
-	// Try COFF
-	var cf *coff.File
-	cf, err = coff.NewFile(r)
-	if err == nil {
-		file.FileType = FileTypeCOFF
+	// Stripped files have no symbol table, which is not an error here
+	var symbols []elf.Symbol
+	symbols, err = ef.Symbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return
+	}
+	// Files without dynamic linking have no dynamic symbols
+	var dynamic []elf.Symbol
+	dynamic, err = ef.DynamicSymbols()
+	if err != nil && err != elf.ErrNoSymbols {
+		return
+	}
+	versions := make(map[string]string)
+	for _, sym := range dynamic {
+		if sym.Version != "" {
+			versions[sym.Name] = sym.Version
+		}
+	}
+	if len(dynamic) > 0 {
+		file.DynamicSymbols = make([]Symbol, len(dynamic))
+	}
+	for i, sym := range dynamic {
+		file.DynamicSymbols[i] = Symbol{
+			Name:    sym.Name,
+			Value:   sym.Value,
+			Size:    sym.Size,
+			Kind:    symbolKindOf(sym.Section),
+			Version: sym.Version,
+		}
+	}
 
-		file.Sections = make([]Section, len(cf.Sections))
-		for i, section := range cf.Sections {
-			file.Sections[i] = &coffSection{section}
+	var sourceFile string
+	file.Symbols = make([]Symbol, len(symbols))
+	for i := 0; i < len(file.Symbols); i++ {
+		switch {
+		case elf.ST_TYPE(symbols[i].Info) == elf.STT_FILE:
+			sourceFile = symbols[i].Name
+		case elf.ST_BIND(symbols[i].Info) == elf.STB_LOCAL:
+			file.Symbols[i].SourceFile = sourceFile
 		}
 
-		var symbols []coff.Symbol
-		symbols, err = cf.Symbols()
-		if err != nil {
-			return
+		file.Symbols[i].Name = symbols[i].Name
+		file.Symbols[i].Value = symbols[i].Value
+		file.Symbols[i].Size = symbols[i].Size
+		file.Symbols[i].Version = elfSymbolVersion(symbols[i].Name, versions)
+		file.Symbols[i].Kind = symbolKindOf(symbols[i].Section)
+	}
+
+	return file, nil
+}
+
+// symbolKindOf returns the kind of an ELF symbol defined in section.
+func symbolKindOf(section elf.SectionIndex) SymbolKind {
+	switch section {
+	case elf.SHN_ABS:
+		return SymbolKindAbsolute
+	case elf.SHN_UNDEF:
+		return SymbolKindUndefined
+	}
+	return SymbolKindRelative
+}
+
+// elfSymbolVersion returns the version of a static symbol. The linker names
+// static symbols bound to a versioned dynamic symbol name@VERSION or
+// name@@VERSION, otherwise the version of the dynamic symbol of the same name
+// is used.
+func elfSymbolVersion(name string, versions map[string]string) string {
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		return strings.TrimPrefix(name[i+1:], "@")
+	}
+	return versions[name]
+}
+
+func newCOFFFile(cf *coff.File, r io.ReaderAt) (file *File, err error) {
+	file = new(File)
+	file.FileType = FileTypeCOFF
+	file.coff = cf
+	file.r = r
+
+	w := writerAt(r)
+	file.Sections = make([]Section, len(cf.Sections))
+	for i, section := range cf.Sections {
+		file.Sections[i] = &coffSection{section, w}
+	}
+
+	var symbols []coff.Symbol
+	symbols, err = cf.Symbols()
+	if err != nil {
+		return
+	}
+	var sourceFile string
+	file.Symbols = make([]Symbol, len(symbols))
+	for i := 0; i < len(file.Symbols); i++ {
+		if symbols[i].StorageClass == coff.C_FILE {
+			sourceFile = symbols[i].Name
+		} else {
+			file.Symbols[i].SourceFile = sourceFile
 		}
-		file.Symbols = make([]Symbol, len(symbols))
-		for i := 0; i < len(file.Symbols); i++ {
-			file.Symbols[i].Name = symbols[i].Name
-			file.Symbols[i].Value = uint64(symbols[i].Value)
-			if symbols[i].AuxiliaryEntry != nil {
-				file.Symbols[i].Size = uint64(symbols[i].AuxiliaryEntry.Size)
-			}
+		file.Symbols[i].Name = symbols[i].Name
+		file.Symbols[i].Value = uint64(symbols[i].Value)
+		if symbols[i].AuxiliaryEntry != nil {
+			file.Symbols[i].Size = uint64(symbols[i].AuxiliaryEntry.Size)
+		}
+		switch symbols[i].SectionNumber {
+		case -1:
+			file.Symbols[i].Kind = SymbolKindAbsolute
+		case 0:
+			file.Symbols[i].Kind = SymbolKindUndefined
+		case -2:
+			file.Symbols[i].Kind = SymbolKindDebug
 		}
-
-		return file, nil
-	} else {
-		es = append(es, fmt.Errorf("debug/coff: %v", err))
 	}
 
-	return nil, es
+	return file, nil
 }
 
 // Open opens a debug file given a path.
@@ -128,6 +308,53 @@ func Open(name string) (*File, error) {
 	return df, nil
 }
 
+// OpenMmap opens a debug file given a path using a read-only memory mapping,
+// so that section reads are copied directly from memory.
+func OpenMmap(name string) (*File, error) {
+	m, err := mmap.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	df, err := NewFile(m)
+	if err != nil {
+		m.Close()
+		return nil, err
+	}
+	df.closer = m
+
+	return df, nil
+}
+
+// WriteTo writes the binary representation of the file to w. COFF files are
+// re-encoded from their parsed contents with a coff.Builder, which fails with
+// coff.ErrLineNumbers if the file has line number entries. ELF files cannot
+// be modified through File, so their original contents are copied unchanged.
+func (f *File) WriteTo(w io.Writer) (n int64, err error) {
+	switch {
+	case f.coff != nil:
+		var b *coff.Builder
+		b, err = coff.NewBuilder(f.coff)
+		if err != nil {
+			return
+		}
+		return b.WriteTo(w)
+	case f.elf != nil:
+		return io.Copy(w, io.NewSectionReader(f.r, 0, 1<<63-1))
+	}
+	return 0, fmt.Errorf("cannot write file type %v", f.FileType)
+}
+
+// AsELF returns the underlying ELF file, ok is false if f is not an ELF file.
+func AsELF(f *File) (ef *elf.File, ok bool) {
+	return f.elf, f.elf != nil
+}
+
+// AsCOFF returns the underlying COFF file, ok is false if f is not a COFF file.
+func AsCOFF(f *File) (cf *coff.File, ok bool) {
+	return f.coff, f.coff != nil
+}
+
 // Close closes the underlying file if there is one.
 func (f *File) Close() error {
 	if f.closer != nil {
@@ -136,22 +363,222 @@ func (f *File) Close() error {
 	return nil
 }
 
+// section returns the first section with the given name.
+func (f *File) section(name string) (Section, bool) {
+	for _, s := range f.Sections {
+		if s.Name() == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Section returns the first section with the given name. An error wrapping
+// ErrSectionNotFound is returned if there is no such section.
+func (f *File) Section(name string) (Section, error) {
+	s, ok := f.section(name)
+	if !ok {
+		return nil, fmt.Errorf("section %s: %w", name, ErrSectionNotFound)
+	}
+	return s, nil
+}
+
+// SectionIndex maps section names to their index in Sections, duplicated
+// names keep the last index. The map is built once and shared, callers must
+// not modify it.
+func (f *File) SectionIndex() map[string]int {
+	f.sectionIndexOnce.Do(func() {
+		f.sectionIndex = make(map[string]int, len(f.Sections))
+		for i, s := range f.Sections {
+			f.sectionIndex[s.Name()] = i
+		}
+	})
+	return f.sectionIndex
+}
+
+// ForEachSection calls fn for each section in file order, stopping early if
+// fn returns false.
+func (f *File) ForEachSection(fn func(s Section) bool) {
+	for _, s := range f.Sections {
+		if !fn(s) {
+			return
+		}
+	}
+}
+
+// SectionNames returns the names of the sections in file order.
+func (f *File) SectionNames() []string {
+	names := make([]string, len(f.Sections))
+	for i, s := range f.Sections {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// SectionReader returns a reader over the raw data of the named section, sized
+// to the section. ErrSectionNotFound is returned if there is no such section.
+//
+// The returned reader is not safe for concurrent use, its Read and Seek
+// methods share a single offset.
+func (f *File) SectionReader(name string) (*io.SectionReader, error) {
+	s, ok := f.section(name)
+	if !ok {
+		return nil, fmt.Errorf("section %s: %w", name, ErrSectionNotFound)
+	}
+	return io.NewSectionReader(s, 0, int64(s.Size())), nil
+}
+
+// SectionData returns the data of the named section, decompressed if needed,
+// or nil for sections without data in the file such as .bss.
+// ErrSectionNotFound is returned if there is no such section.
+func (f *File) SectionData(name string) ([]byte, error) {
+	s, ok := f.section(name)
+	if !ok {
+		return nil, fmt.Errorf("section %s: %w", name, ErrSectionNotFound)
+	}
+	if f.coff != nil {
+		return f.coff.SectionData(name)
+	}
+	if s, ok := s.(*elfSection); ok && s.s.Type == elf.SHT_NOBITS {
+		return nil, nil
+	}
+	data := make([]byte, s.UncompressedSize())
+	if _, err := io.ReadFull(io.NewSectionReader(s, 0, int64(len(data))), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// VersionString reads the null-terminated string at offset within the named
+// section, typically a firmware version string.
+//
+// The TI tools do not reserve a section for this, by convention the string is
+// a const char array placed in a dedicated section such as ".version" or
+// ".buildinfo" with the DATA_SECTION pragma (or the section attribute), and
+// kept by the linker command file at a fixed address. The offset is relative
+// to the start of the section.
+func (f *File) VersionString(sectionName string, offset uint64) (string, error) {
+	s, ok := f.section(sectionName)
+	if !ok {
+		return "", fmt.Errorf("section %s: %w", sectionName, ErrSectionNotFound)
+	}
+	return s.ReadString(int64(offset))
+}
+
+// readString implements Section.ReadString.
+func readString(s Section, off int64) (string, error) {
+	if off < 0 || uint64(off) >= s.Size() {
+		return "", fmt.Errorf("section %s: offset %d exceeds size %d", s.Name(), off, s.Size())
+	}
+
+	bs, err := bufio.NewReader(io.NewSectionReader(s, off, int64(s.Size())-off)).ReadBytes(0x00)
+	if err == io.EOF {
+		return "", fmt.Errorf("section %s: string at offset %d: %w", s.Name(), off, ErrSectionTruncated)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(bs[:len(bs)-1]), nil
+}
+
+// Symbol returns the first symbol with the given name. An error wrapping
+// ErrSymbolNotFound is returned if there is no such symbol.
+func (f *File) Symbol(name string) (Symbol, error) {
+	for _, symbol := range f.Symbols {
+		if symbol.Name == name {
+			return symbol, nil
+		}
+	}
+	return Symbol{}, fmt.Errorf("symbol %s: %w", name, ErrSymbolNotFound)
+}
+
+// SymbolsMatching returns all symbols whose names match the regular expression
+// pattern. An error is returned if pattern fails to compile.
+func (f *File) SymbolsMatching(pattern string) ([]Symbol, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbols []Symbol
+	for _, symbol := range f.Symbols {
+		if re.MatchString(symbol.Name) {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// A Section is a section of a debug file. The offsets given to ReadAt are
+// relative to the start of the section data, not to the file.
 type Section interface {
 	io.ReaderAt
+
+	// Open returns a new ReadSeeker reading the section data from its
+	// start. Each call returns an independent reader with its own offset,
+	// but a single reader must not be shared between goroutines.
 	Open() io.ReadSeeker
 
 	Name() string
 	Address() uint64
 	Size() uint64
+	Type() SectionType
+
+	// AddressRange is the range of addresses occupied by the section.
+	AddressRange() AddressRange
+
+	// Group is the signature of the ELF section group the section belongs
+	// to, or "" if there is none.
+	Group() string
+
+	// Compressed reports whether the section data is compressed in the
+	// file (ELF SHF_COMPRESSED). Size, ReadAt and Open refer to the
+	// uncompressed data.
+	Compressed() bool
+
+	// UncompressedSize is the size of the section data after
+	// decompression, equal to Size.
+	UncompressedSize() uint64
+
+	// Alignment is the required alignment of the section address in bytes.
+	Alignment() uint64
+
+	// HexDump writes a hex dump of the section data to w.
+	HexDump(w io.Writer) error
+
+	// ReadString returns the null-terminated string starting at off. It
+	// fails with ErrSectionTruncated if the section ends before the
+	// terminator.
+	ReadString(off int64) (string, error)
+
+	// Fill sets the section bytes in the range [start, end) to value. It
+	// fails with ErrReadOnly unless the file was created by ParseBytes.
+	Fill(value byte, start, end uint64) error
+
+	// WriteAt writes p to the section data at offset off. Like Fill, it
+	// fails with ErrReadOnly unless the file was created by ParseBytes.
+	// Headers are not updated, TI-COFF and ELF have no header checksum.
+	io.WriterAt
 }
 
 var _ Section = (*coffSection)(nil)
 
 type coffSection struct {
 	s *coff.Section
+
+	// w is the underlying file if it is writable, otherwise nil.
+	w io.WriterAt
 }
 
+// ReadAt reads from the section's raw data. A read starting before the
+// section or past its end, which is a wrong offset rather than reading up to
+// the end, fails with ErrOutOfRange. A read running over the end returns the
+// data up to it and io.EOF, as documented by io.ReaderAt.
 func (section *coffSection) ReadAt(p []byte, off int64) (n int, err error) {
+	if size := section.s.ByteSize(); off < 0 || off > size {
+		return 0, fmt.Errorf("ReadAt: section '%s' read [%d, %d) exceeds size %d: %w",
+			section.Name(), off, off+int64(len(p)), size, ErrOutOfRange)
+	}
 	return section.s.ReadAt(p, off)
 }
 
@@ -171,14 +598,92 @@ func (section *coffSection) Size() uint64 {
 	return uint64(section.s.Size)
 }
 
+func (section *coffSection) Alignment() uint64 {
+	return uint64(section.s.Alignment())
+}
+
+func (section *coffSection) AddressRange() AddressRange {
+	return sectionAddressRange(section)
+}
+
+func (section *coffSection) Group() string {
+	return ""
+}
+
+func (section *coffSection) Compressed() bool {
+	return false
+}
+
+func (section *coffSection) UncompressedSize() uint64 {
+	return section.Size()
+}
+
+func (section *coffSection) Type() SectionType {
+	return coffSectionType(section.s)
+}
+
+func (section *coffSection) HexDump(w io.Writer) error {
+	return hexDump(w, section.Open())
+}
+
+func (section *coffSection) ReadString(off int64) (string, error) {
+	return readString(section, off)
+}
+
+func (section *coffSection) WriteAt(p []byte, off int64) (n int, err error) {
+	if section.w == nil {
+		return 0, ErrReadOnly
+	}
+	if off < 0 || uint64(off)+uint64(len(p)) > section.Size() {
+		return 0, fmt.Errorf("section %s: write [%d, %d) exceeds size %d", section.Name(), off, off+int64(len(p)), section.Size())
+	}
+	if section.s.RawDataAddress == 0 {
+		return 0, fmt.Errorf("section %s: no raw data", section.Name())
+	}
+	return section.w.WriteAt(p, int64(section.s.RawDataAddress)+off)
+}
+
+func (section *coffSection) Fill(value byte, start, end uint64) error {
+	if section.w == nil {
+		return ErrReadOnly
+	}
+	if start > end || end > section.Size() {
+		return fmt.Errorf("section %s: fill range [%d, %d) exceeds size %d", section.Name(), start, end, section.Size())
+	}
+	if section.s.RawDataAddress == 0 {
+		return fmt.Errorf("section %s: no raw data", section.Name())
+	}
+	return fill(section.w, value, int64(section.s.RawDataAddress)+int64(start), end-start)
+}
+
 var _ Section = (*elfSection)(nil)
 
 type elfSection struct {
 	s *elf.Section
+
+	// w is the underlying file if it is writable, otherwise nil.
+	w io.WriterAt
+
+	// file and index locate the section for looking up its group.
+	file  *File
+	index int
 }
 
 func (section *elfSection) ReadAt(p []byte, off int64) (n int, err error) {
-	return section.s.ReadAt(p, off)
+	if !section.Compressed() {
+		return section.s.ReadAt(p, off)
+	}
+
+	// Compressed data can only be read sequentially
+	r := section.s.Open()
+	if _, err = r.Seek(off, io.SeekStart); err != nil {
+		return
+	}
+	n, err = io.ReadFull(r, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return
 }
 
 func (section *elfSection) Open() io.ReadSeeker {
@@ -197,10 +702,114 @@ func (section *elfSection) Size() uint64 {
 	return uint64(section.s.Size)
 }
 
+func (section *elfSection) Alignment() uint64 {
+	return section.s.Addralign
+}
+
+func (section *elfSection) AddressRange() AddressRange {
+	return sectionAddressRange(section)
+}
+
+func (section *elfSection) Group() string {
+	section.file.loadGroups()
+	return section.file.groupMembership[section.index]
+}
+
+func (section *elfSection) Compressed() bool {
+	return section.s.Flags&elf.SHF_COMPRESSED != 0
+}
+
+func (section *elfSection) UncompressedSize() uint64 {
+	return section.s.Size
+}
+
+func (section *elfSection) Type() SectionType {
+	return elfSectionType(section.s)
+}
+
+func (section *elfSection) HexDump(w io.Writer) error {
+	return hexDump(w, section.Open())
+}
+
+func (section *elfSection) ReadString(off int64) (string, error) {
+	return readString(section, off)
+}
+
+func (section *elfSection) WriteAt(p []byte, off int64) (n int, err error) {
+	if section.w == nil {
+		return 0, ErrReadOnly
+	}
+	if off < 0 || uint64(off)+uint64(len(p)) > section.Size() {
+		return 0, fmt.Errorf("section %s: write [%d, %d) exceeds size %d", section.Name(), off, off+int64(len(p)), section.Size())
+	}
+	if section.s.Type == elf.SHT_NOBITS || section.Compressed() {
+		return 0, fmt.Errorf("section %s: no uncompressed file data", section.Name())
+	}
+	return section.w.WriteAt(p, int64(section.s.Offset)+off)
+}
+
+func (section *elfSection) Fill(value byte, start, end uint64) error {
+	if section.w == nil {
+		return ErrReadOnly
+	}
+	if start > end || end > section.Size() {
+		return fmt.Errorf("section %s: fill range [%d, %d) exceeds size %d", section.Name(), start, end, section.Size())
+	}
+	if section.s.Type == elf.SHT_NOBITS || section.s.Flags&elf.SHF_COMPRESSED != 0 {
+		return fmt.Errorf("section %s: no uncompressed file data", section.Name())
+	}
+	return fill(section.w, value, int64(section.s.Offset)+int64(start), end-start)
+}
+
 type Symbol struct {
 	Name  string
 	Value uint64
 	Size  uint64
+	Kind  SymbolKind
+
+	// Version is the GNU symbol version of an ELF symbol, such as
+	// "GLIBC_2.17", or "" if it has none. It is read from the .gnu.version,
+	// .gnu.version_d and .gnu.version_r sections, which only cover the
+	// dynamic symbols, see File.DynamicSymbols.
+	Version string
+
+	// SourceFile is the name of the source file the symbol was defined in,
+	// taken from the preceding file symbol (C_FILE for COFF, STT_FILE for
+	// ELF), or "" if it is unknown. ELF only records this for local symbols.
+	SourceFile string
+}
+
+// A SymbolKind tells what the value of a symbol is relative to.
+type SymbolKind int
+
+const (
+	// SymbolKindRelative symbols are defined in a section.
+	SymbolKindRelative SymbolKind = iota
+
+	// SymbolKindAbsolute symbols have a constant value, such as the
+	// linker-generated _bss_size.
+	SymbolKindAbsolute
+
+	// SymbolKindUndefined symbols are defined in another file.
+	SymbolKindUndefined
+
+	// SymbolKindDebug symbols carry debugging information and have no
+	// address, such as COFF file names.
+	SymbolKindDebug
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SymbolKindRelative:
+		return "Relative"
+	case SymbolKindAbsolute:
+		return "Absolute"
+	case SymbolKindUndefined:
+		return "Undefined"
+	case SymbolKindDebug:
+		return "Debug"
+	}
+	return fmt.Sprintf("SymbolKind%d", int(k))
 }
 
 type ErrorSlice []error
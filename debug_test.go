@@ -0,0 +1,324 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// testCOFF returns a little-endian MSP430 executable with a .text and a .bss
+// section.
+// testCOFFBuilder returns a Builder for a little-endian MSP430 executable
+// with a .text and a .bss section.
+func testCOFFBuilder() *coff.Builder {
+	return &coff.Builder{
+		FileHeader: coff.FileHeader{
+			Version:  0xC2,
+			Flags:    coff.FLAG_LITTLE | coff.FLAG_EXEC,
+			TargetID: 0x00A0,
+		},
+		Sections: []*coff.BuilderSection{
+			{
+				SectionHeader: coff.SectionHeader{Name: ".text", PhysicalAddress: 0x1000, VirtualAddress: 0x1000, Flags: coff.STYP_TEXT},
+				Data:          []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			},
+			{
+				SectionHeader: coff.SectionHeader{Name: ".bss", PhysicalAddress: 0x2000, VirtualAddress: 0x2000, Size: 16, Flags: coff.STYP_BSS},
+			},
+		},
+		Symbols: []coff.Symbol{
+			{Name: ".text", Value: 0x1000, SectionNumber: 1, StorageClass: coff.C_STAT},
+			{Name: "_main", Value: 0x1002, SectionNumber: 1, StorageClass: coff.C_EXT},
+		},
+	}
+}
+
+func testCOFF(t *testing.T) *File {
+	data, err := testCOFFBuilder().Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestCOFFBigEndian(t *testing.T) {
+	b := testCOFFBuilder()
+	b.FileHeader.Flags = coff.FLAG_BIG | coff.FLAG_EXEC
+	data, err := b.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sym, err := f.Symbol("_main"); err != nil || sym.Value != 0x1002 {
+		t.Errorf("Symbol(_main) = %+v, %v", sym, err)
+	}
+	if data, err := f.SectionData(".text"); err != nil || !bytes.Equal(data, []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("SectionData(.text) = % x, %v", data, err)
+	}
+}
+
+func TestCOFFSectionReadAtEOF(t *testing.T) {
+	f := testCOFF(t)
+	s, ok := f.section(".text")
+	if !ok {
+		t.Fatal("no .text section")
+	}
+
+	p := make([]byte, 4)
+	n, err := s.ReadAt(p, 6)
+	if n != 2 || err != io.EOF {
+		t.Errorf("ReadAt past end = %d, %v, want 2, io.EOF", n, err)
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(s, 0, int64(s.Size())+1))
+	if err != nil || !bytes.Equal(data, []byte{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("ReadAll = %v, %v", data, err)
+	}
+	if n, err := s.ReadAt(p, 8); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt at end = %d, %v, want 0, io.EOF", n, err)
+	}
+
+	for _, off := range []int64{-1, 9, 1024} {
+		n, err := s.ReadAt(p, off)
+		if n != 0 || !errors.Is(err, ErrOutOfRange) {
+			t.Errorf("ReadAt(%d) = %d, %v, want ErrOutOfRange", off, n, err)
+		}
+	}
+	_, err = s.ReadAt(p, 1024)
+	if want := "ReadAt: section '.text' read [1024, 1028) exceeds size 8: read out of section range"; err == nil || err.Error() != want {
+		t.Errorf("ReadAt(1024) error = %v, want %q", err, want)
+	}
+}
+
+// benchSymbolCount is the number of symbols of benchFile.
+const benchSymbolCount = 10000
+
+// benchFile returns a COFF file with benchSymbolCount function symbols 4
+// bytes apart in its .text section.
+func benchFile(b *testing.B) *File {
+	builder := &coff.Builder{
+		FileHeader: coff.FileHeader{
+			Version:  0xC2,
+			Flags:    coff.FLAG_LITTLE | coff.FLAG_EXEC,
+			TargetID: 0x00A0,
+		},
+		Sections: []*coff.BuilderSection{{
+			SectionHeader: coff.SectionHeader{Name: ".text", PhysicalAddress: 0x1000, VirtualAddress: 0x1000, Flags: coff.STYP_TEXT},
+			Data:          make([]byte, 4*benchSymbolCount),
+		}},
+		Symbols: make([]coff.Symbol, benchSymbolCount),
+	}
+	for i := range builder.Symbols {
+		builder.Symbols[i] = coff.Symbol{
+			Name:          fmt.Sprintf("_func%05d", i),
+			Value:         0x1000 + uint32(4*i),
+			SectionNumber: 1,
+			StorageClass:  coff.C_EXT,
+		}
+	}
+
+	data, err := builder.Bytes()
+	if err != nil {
+		b.Fatal(err)
+	}
+	f, err := NewFile(bytes.NewReader(data))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return f
+}
+
+func BenchmarkSymbolByName(b *testing.B) {
+	f := benchFile(b)
+
+	b.Run("Symbol", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := f.Symbol(f.Symbols[i%len(f.Symbols)].Name); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("LookupTable", func(b *testing.B) {
+		t := f.BuildLookupTable()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, ok := t.ByName(f.Symbols[i%len(f.Symbols)].Name); !ok {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+}
+
+func BenchmarkSymbolAt(b *testing.B) {
+	f := benchFile(b)
+	addr := func(i int) uint64 {
+		return 0x1000 + uint64(i*4%(4*benchSymbolCount)) + 2
+	}
+
+	b.Run("Linear", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			a := addr(i)
+			var nearest *Symbol
+			for j := range f.Symbols {
+				sym := &f.Symbols[j]
+				if sym.Value <= a && (nearest == nil || sym.Value > nearest.Value) {
+					nearest = sym
+				}
+			}
+			if nearest == nil {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+	b.Run("LookupTable", func(b *testing.B) {
+		t := f.BuildLookupTable()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, ok := t.Nearest(addr(i)); !ok {
+				b.Fatal("symbol not found")
+			}
+		}
+	})
+}
+
+func TestSectionDataNoBits(t *testing.T) {
+	f := testCOFF(t)
+	if data, err := f.SectionData(".bss"); data != nil || err != nil {
+		t.Errorf("COFF .bss: SectionData() = %v, %v, want nil, nil", data, err)
+	}
+
+	name, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	ef, err := Open(name)
+	if err != nil || ef.FileType != FileTypeELF {
+		t.Skip("test binary is not an ELF file")
+	}
+	defer ef.Close()
+	for _, s := range ef.Sections {
+		if s, ok := s.(*elfSection); ok && s.s.Type == elf.SHT_NOBITS && s.Size() > 0 {
+			if data, err := ef.SectionData(s.Name()); data != nil || err != nil {
+				t.Errorf("ELF %s: SectionData() = %d bytes, %v, want nil, nil", s.Name(), len(data), err)
+			}
+			return
+		}
+	}
+	t.Skip("test binary has no SHT_NOBITS section")
+}
+
+func TestSymbolNotFound(t *testing.T) {
+	f := testCOFF(t)
+	if sym, err := f.Symbol("_main"); err != nil || sym.Value != 0x1002 {
+		t.Errorf("Symbol(_main) = %+v, %v", sym, err)
+	}
+
+	_, err := f.Symbol("_missing")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("Symbol(_missing) error = %v, want ErrSymbolNotFound", err)
+	}
+	_, cerr := f.coff.Symbol("_missing")
+	if !errors.Is(cerr, coff.ErrSymbolNotFound) || err.Error() != cerr.Error() {
+		t.Errorf("coff error %q does not match debug error %q", cerr, err)
+	}
+}
+
+func TestSectionNotFound(t *testing.T) {
+	f := testCOFF(t)
+	if s, err := f.Section(".text"); err != nil || s.Name() != ".text" {
+		t.Errorf("Section(.text) = %v, %v", s, err)
+	}
+
+	_, err := f.Section(".missing")
+	if !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Section(.missing) error = %v, want ErrSectionNotFound", err)
+	}
+	_, cerr := f.coff.Section(".missing")
+	if !errors.Is(cerr, coff.ErrSectionNotFound) || err.Error() != cerr.Error() {
+		t.Errorf("coff error %q does not match debug error %q", cerr, err)
+	}
+
+	_, rerr := f.SectionReader(".missing")
+	_, derr := f.SectionData(".missing")
+	_, verr := f.VersionString(".missing", 0)
+	for fn, e := range map[string]error{"SectionReader": rerr, "SectionData": derr, "VersionString": verr} {
+		if !errors.Is(e, ErrSectionNotFound) || e.Error() != err.Error() {
+			t.Errorf("%s error = %v, want %q", fn, e, err)
+		}
+	}
+}
+
+func TestObjdumpSymbolsSkipsSectionSymbols(t *testing.T) {
+	f := testCOFF(t)
+	var buf bytes.Buffer
+	if err := f.ObjdumpSymbols(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "00001002 00000000 T _main\n"; buf.String() != want {
+		t.Errorf("ObjdumpSymbols wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadMemoryTrailingBSS(t *testing.T) {
+	f := testCOFF(t)
+	for _, tt := range []struct {
+		addr, size uint64
+		want       []byte
+		err        error
+	}{
+		{0x1006, 4, []byte{7, 8, 0xFF, 0xFF}, nil},
+		{0x2000, 4, []byte{0xFF, 0xFF, 0xFF, 0xFF}, nil},
+		{0x200E, 4, []byte{0xFF, 0xFF}, io.EOF},
+		{0x2010, 4, nil, io.EOF},
+	} {
+		got, err := f.ReadMemory(tt.addr, tt.size)
+		if !bytes.Equal(got, tt.want) || err != tt.err {
+			t.Errorf("ReadMemory(%#x, %d) = % x, %v, want % x, %v", tt.addr, tt.size, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestStrippedSharedLibraryVersions(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "libversioned.so"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if len(f.Symbols) != 0 {
+		t.Errorf("stripped library has %d symbols, want none", len(f.Symbols))
+	}
+	var found bool
+	for _, sym := range f.DynamicSymbols {
+		if sym.Name == "answer" {
+			found = true
+			if sym.Version != "LIBT_1.0" || sym.Kind != SymbolKindRelative || sym.Size == 0 {
+				t.Errorf("dynamic symbol answer = %+v, want version LIBT_1.0", sym)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("DynamicSymbols = %+v, want answer", f.DynamicSymbols)
+	}
+
+	if f := testCOFF(t); f.DynamicSymbols != nil {
+		t.Errorf("COFF DynamicSymbols = %+v, want nil", f.DynamicSymbols)
+	}
+}
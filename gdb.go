@@ -0,0 +1,46 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// WriteGDBSymFile writes a symbol file for GDB to w, an ELF file holding the
+// section addresses and symbols of f, which can be loaded with symbol-file or
+// add-symbol-file.
+//
+// COFF files are converted with coff.File.WriteELF and marked as executables,
+// so that GDB places the sections at their recorded addresses without further
+// arguments. The limitations of WriteELF apply, in particular there is no line
+// number or DWARF information. ELF files are written unchanged.
+func (f *File) WriteGDBSymFile(w io.Writer) error {
+	switch {
+	case f.coff != nil:
+		var buf bytes.Buffer
+		if err := f.coff.WriteELF(&buf); err != nil {
+			return err
+		}
+
+		// e_type follows the 16 identification bytes
+		b := buf.Bytes()
+		var bo binary.ByteOrder = binary.LittleEndian
+		if elf.Data(b[elf.EI_DATA]) == elf.ELFDATA2MSB {
+			bo = binary.BigEndian
+		}
+		bo.PutUint16(b[elf.EI_NIDENT:], uint16(elf.ET_EXEC))
+
+		_, err := w.Write(b)
+		return err
+
+	case f.elf != nil:
+		_, err := f.WriteTo(w)
+		return err
+	}
+	return fmt.Errorf("cannot write symbol file for file type %v", f.FileType)
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"debug/elf"
+	"fmt"
+)
+
+// grpComdat is the GRP_COMDAT flag of an ELF section group.
+const grpComdat = 0x1
+
+// An ELFGroup is an ELF section group (SHT_GROUP), a set of sections the
+// linker keeps or discards together. COMDAT groups hold the code and data of
+// template instantiations and inline functions, only one group with a given
+// signature is kept. It is unrelated to coff.SectionGroup, which reads the
+// data of several COFF sections concurrently.
+type ELFGroup struct {
+	// Signature is the name of the symbol identifying the group.
+	Signature string
+
+	// Comdat is set for COMDAT groups.
+	Comdat bool
+
+	// Sections are the names of the member sections.
+	Sections []string
+
+	// indexes are the section indexes of the members.
+	indexes []int
+}
+
+// An SHTGroup is an ELF section group with its member sections. See ELFGroup
+// for the difference from coff.SectionGroup.
+type SHTGroup struct {
+	Signature string
+	Members   []Section
+}
+
+// SHTGroups returns the section groups of an ELF file with their member
+// sections, in file order. COFF files have no section groups, the result is
+// empty. An error is returned if the SHT_GROUP sections are malformed.
+func (f *File) SHTGroups() ([]SHTGroup, error) {
+	f.loadGroups()
+	if f.groupsErr != nil {
+		return nil, f.groupsErr
+	}
+
+	groups := make([]SHTGroup, len(f.groups))
+	for i, g := range f.groups {
+		groups[i].Signature = g.Signature
+		groups[i].Members = make([]Section, len(g.indexes))
+		for j, index := range g.indexes {
+			groups[i].Members[j] = f.Sections[index]
+		}
+	}
+	return groups, nil
+}
+
+// SectionGroups returns the section groups of an ELF file, in file order.
+// COFF files have no section groups. If the SHT_GROUP sections are malformed
+// the result is empty, SHTGroups reports the error.
+func (f *File) SectionGroups() []ELFGroup {
+	f.loadGroups()
+	return f.groups
+}
+
+// loadGroups reads the section groups of an ELF file on first use, so that
+// malformed groups do not prevent the file from being opened.
+func (f *File) loadGroups() {
+	f.groupsOnce.Do(func() {
+		if f.elf != nil {
+			f.groups, f.groupMembership, f.groupsErr = elfSectionGroups(f.elf)
+		}
+	})
+}
+
+// elfSectionGroups reads the SHT_GROUP sections of ef. It returns the groups
+// and the signature of the group of each member section, by section index.
+func elfSectionGroups(ef *elf.File) (groups []ELFGroup, membership map[int]string, err error) {
+	membership = make(map[int]string)
+	symbols, _ := ef.Symbols()
+
+	for _, s := range ef.Sections {
+		if s.Type != elf.SHT_GROUP {
+			continue
+		}
+
+		var data []byte
+		data, err = s.Data()
+		if err != nil {
+			return
+		}
+		if len(data) < 4 || len(data)%4 != 0 {
+			return nil, nil, fmt.Errorf("section %s: invalid group size %d", s.Name, len(data))
+		}
+
+		// The signature is the sh_info symbol, elf.File.Symbols omits the
+		// null symbol at index 0
+		var group ELFGroup
+		if s.Info > 0 && int(s.Info) <= len(symbols) {
+			group.Signature = symbols[s.Info-1].Name
+		}
+		group.Comdat = ef.ByteOrder.Uint32(data)&grpComdat != 0
+
+		for i := 4; i < len(data); i += 4 {
+			index := int(ef.ByteOrder.Uint32(data[i:]))
+			if index >= len(ef.Sections) {
+				return nil, nil, fmt.Errorf("section %s: invalid member section index %d", s.Name, index)
+			}
+			group.Sections = append(group.Sections, ef.Sections[index].Name)
+			group.indexes = append(group.indexes, index)
+			membership[index] = group.Signature
+		}
+		groups = append(groups, group)
+	}
+	return
+}
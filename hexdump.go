@@ -0,0 +1,55 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// hexDump writes the data read from r to w in the style of xxd: the offset,
+// 16 bytes in hex split into two groups of 8, and the printable ASCII.
+func hexDump(w io.Writer, r io.Reader) error {
+	bw := bufio.NewWriter(w)
+
+	var (
+		line   [16]byte
+		offset int
+	)
+	for {
+		n, err := io.ReadFull(r, line[:])
+		if n > 0 {
+			fmt.Fprintf(bw, "%08x: ", offset)
+			for i := 0; i < len(line); i++ {
+				if i == 8 {
+					bw.WriteByte(' ')
+				}
+				if i < n {
+					fmt.Fprintf(bw, "%02x ", line[i])
+				} else {
+					bw.WriteString("   ")
+				}
+			}
+			bw.WriteByte(' ')
+			for _, b := range line[:n] {
+				if b < 0x20 || b > 0x7E {
+					b = '.'
+				}
+				bw.WriteByte(b)
+			}
+			bw.WriteByte('\n')
+			offset += n
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package mmap_test
+
+import (
+	"io"
+	"syscall"
+	"testing"
+
+	debug "github.com/awarepoint/go-debug"
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// maxFD is the highest file descriptor checked by openFDs.
+const maxFD = 1024
+
+// openFDs returns the descriptor flags of the open file descriptors.
+func openFDs() map[int]uintptr {
+	fds := make(map[int]uintptr)
+	for fd := 0; fd < maxFD; fd++ {
+		flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, uintptr(fd), syscall.F_GETFD, 0)
+		if errno == 0 {
+			fds[fd] = flags
+		}
+	}
+	return fds
+}
+
+func TestOpenCloseOnExec(t *testing.T) {
+	name := testFile(t)
+	for _, test := range []struct {
+		name string
+		open func(string) (io.Closer, error)
+	}{
+		{"coff.Open", func(name string) (io.Closer, error) { return coff.Open(name) }},
+		{"debug.Open", func(name string) (io.Closer, error) { return debug.Open(name) }},
+	} {
+		before := openFDs()
+		f, err := test.open(name)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		opened := 0
+		for fd, flags := range openFDs() {
+			if _, ok := before[fd]; ok {
+				continue
+			}
+			opened++
+			if flags&syscall.FD_CLOEXEC == 0 {
+				t.Errorf("%s: file descriptor %d does not have FD_CLOEXEC set", test.name, fd)
+			}
+		}
+		if opened == 0 {
+			t.Errorf("%s: no new file descriptor found", test.name)
+		}
+		f.Close()
+	}
+}
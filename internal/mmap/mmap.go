@@ -0,0 +1,79 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+// Package mmap provides read-only memory mapped access to files.
+package mmap
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+var errClosed = errors.New("mmap: closed")
+
+// A ReaderAt reads from a read-only memory mapping of a file.
+type ReaderAt struct {
+	data []byte
+}
+
+// Open memory maps the named file for reading.
+func Open(name string) (*ReaderAt, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Mapping an empty file fails on most platforms
+	if fi.Size() == 0 {
+		return &ReaderAt{data: []byte{}}, nil
+	}
+	if int64(int(fi.Size())) != fi.Size() {
+		return nil, errors.New("mmap: file too large")
+	}
+
+	data, err := mmap(f, int(fi.Size()))
+	if err != nil {
+		return nil, err
+	}
+	return &ReaderAt{data: data}, nil
+}
+
+// Len returns the length of the mapping.
+func (r *ReaderAt) Len() int {
+	return len(r.data)
+}
+
+// ReadAt implements io.ReaderAt by copying directly from the mapping.
+func (r *ReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if r.data == nil {
+		return 0, errClosed
+	}
+	if off < 0 || off > int64(len(r.data)) {
+		return 0, io.EOF
+	}
+	n = copy(p, r.data[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+// Close unmaps the file. The ReaderAt must not be used afterwards.
+func (r *ReaderAt) Close() error {
+	if r.data == nil {
+		return nil
+	}
+	data := r.data
+	r.data = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return munmap(data)
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+//go:build !linux && !darwin && !windows
+// +build !linux,!darwin,!windows
+
+package mmap
+
+import (
+	"errors"
+	"os"
+)
+
+var errUnsupported = errors.New("mmap: not supported on this platform")
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	return nil, errUnsupported
+}
+
+func munmap(data []byte) error {
+	return errUnsupported
+}
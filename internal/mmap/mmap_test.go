@@ -0,0 +1,85 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package mmap_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// benchSectionSize is the size of the .text section of the benchmark file.
+const benchSectionSize = 1 << 20
+
+// testFile writes a COFF file with a single 1 MiB .text section to a
+// temporary file and returns its name.
+func testFile(tb testing.TB) string {
+	text := make([]byte, benchSectionSize)
+	for i := range text {
+		text[i] = byte(i)
+	}
+	builder := &coff.Builder{
+		FileHeader: coff.FileHeader{
+			Version:  0xC2,
+			Flags:    coff.FLAG_LITTLE | coff.FLAG_EXEC,
+			TargetID: 0x0097,
+		},
+		Sections: []*coff.BuilderSection{{
+			SectionHeader: coff.SectionHeader{Name: ".text", Flags: coff.STYP_TEXT},
+			Data:          text,
+		}},
+	}
+	data, err := builder.Bytes()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	name := filepath.Join(tb.TempDir(), "test.out")
+	if err = os.WriteFile(name, data, 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return name
+}
+
+func benchmarkOpen(b *testing.B, open func(string) (*coff.File, error)) {
+	name := testFile(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f, err := open(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func BenchmarkOpenFile(b *testing.B) { benchmarkOpen(b, coff.Open) }
+func BenchmarkOpenMmap(b *testing.B) { benchmarkOpen(b, coff.OpenMmap) }
+
+// benchmarkSectionRead reads the .text section in 4 KiB blocks, the size of a
+// typical flash page.
+func benchmarkSectionRead(b *testing.B, open func(string) (*coff.File, error)) {
+	f, err := open(testFile(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	s := f.Sections[0]
+
+	p := make([]byte, 4096)
+	b.SetBytes(benchSectionSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for off := int64(0); off < benchSectionSize; off += int64(len(p)) {
+			if _, err = s.ReadAt(p, off); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSectionReadFile(b *testing.B) { benchmarkSectionRead(b, coff.Open) }
+func BenchmarkSectionReadMmap(b *testing.B) { benchmarkSectionRead(b, coff.OpenMmap) }
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import "sort"
+
+// A SymbolLookupTable indexes the symbols of a file by name and by address,
+// for callers doing many lookups. It is immutable once built and safe for
+// concurrent use.
+type SymbolLookupTable struct {
+	byName map[string]Symbol
+
+	// byAddr holds the symbols that have an address, sorted by Value.
+	byAddr []Symbol
+}
+
+// BuildLookupTable builds a SymbolLookupTable from the symbols of the file.
+// Undefined and debugging symbols have no address and can only be looked up
+// by name.
+func (f *File) BuildLookupTable() *SymbolLookupTable {
+	t := &SymbolLookupTable{
+		byName: make(map[string]Symbol, len(f.Symbols)),
+	}
+	for _, sym := range f.Symbols {
+		if _, ok := t.byName[sym.Name]; !ok && sym.Name != "" {
+			t.byName[sym.Name] = sym
+		}
+		if sym.Kind == SymbolKindRelative || sym.Kind == SymbolKindAbsolute {
+			t.byAddr = append(t.byAddr, sym)
+		}
+	}
+	sort.SliceStable(t.byAddr, func(i, j int) bool {
+		return t.byAddr[i].Value < t.byAddr[j].Value
+	})
+	return t
+}
+
+// ByName returns the symbol with the given name. If several symbols share the
+// name the first one in the symbol table is returned.
+func (t *SymbolLookupTable) ByName(name string) (Symbol, bool) {
+	sym, ok := t.byName[name]
+	return sym, ok
+}
+
+// Nearest returns the symbol with the highest address not above addr, ok is
+// false if there is none. If several symbols share that address the last one
+// in the symbol table is returned.
+func (t *SymbolLookupTable) Nearest(addr uint64) (sym Symbol, ok bool) {
+	i := sort.Search(len(t.byAddr), func(i int) bool {
+		return t.byAddr[i].Value > addr
+	})
+	if i == 0 {
+		return
+	}
+	return t.byAddr[i-1], true
+}
+
+// InRange returns the symbols with an address in [start, end), sorted by
+// address.
+func (t *SymbolLookupTable) InRange(start, end uint64) []Symbol {
+	i := sort.Search(len(t.byAddr), func(i int) bool {
+		return t.byAddr[i].Value >= start
+	})
+	j := sort.Search(len(t.byAddr), func(i int) bool {
+		return t.byAddr[i].Value >= end
+	})
+	if i >= j {
+		return nil
+	}
+	return append([]Symbol(nil), t.byAddr[i:j]...)
+}
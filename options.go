@@ -0,0 +1,33 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+// An Option configures how NewFile detects and parses a file.
+type Option func(*options)
+
+type options struct {
+	preferredType FileType
+}
+
+// WithPreferredType makes NewFile try parsing the file as type t before any
+// other file type. Files of the expected type are then parsed without first
+// running the other parsers, whose errors would otherwise be reported.
+func WithPreferredType(t FileType) Option {
+	return func(o *options) {
+		o.preferredType = t
+	}
+}
+
+// detectionOrder returns the file types to try, in order.
+func (o *options) detectionOrder() []FileType {
+	order := []FileType{FileTypeELF, FileTypeCOFF}
+	for i, t := range order {
+		if t == o.preferredType {
+			copy(order[1:i+1], order[:i])
+			order[0] = t
+			break
+		}
+	}
+	return order
+}
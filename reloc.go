@@ -0,0 +1,188 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"debug/elf"
+	"fmt"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// A Reloc is a relocation entry of a section.
+type Reloc struct {
+	// Section is the name of the section being relocated.
+	Section string
+
+	// Offset is the location of the relocated field as recorded in the file,
+	// for relocatable files this is relative to the start of the section.
+	Offset uint64
+
+	// Symbol is the name of the referenced symbol. References to sections are
+	// named after the section.
+	Symbol string
+
+	// Type is the format-specific relocation type.
+	Type uint32
+
+	// symbolSection is the name of the section the referenced symbol is
+	// defined in, or "" if it is undefined or absolute.
+	symbolSection string
+}
+
+// Relocs returns the relocations of all sections.
+func (f *File) Relocs() ([]Reloc, error) {
+	switch {
+	case f.elf != nil:
+		return elfRelocs(f.elf)
+	case f.coff != nil:
+		return coffRelocs(f.coff)
+	}
+	return nil, nil
+}
+
+func coffRelocs(cf *coff.File) (relocs []Reloc, err error) {
+	for _, s := range cf.Sections {
+		var entries []coff.RelocationEntry
+		if entries, err = s.Relocations(); err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			reloc := Reloc{
+				Section: s.Name,
+				Offset:  uint64(entry.VirtualAddress),
+				Type:    uint32(entry.Type),
+			}
+			if entry.IsInternal() {
+				reloc.Symbol = s.Name
+				reloc.symbolSection = s.Name
+			} else if sym, ok := cf.SymbolByIndex(entry.SymbolTableIndex); ok {
+				reloc.Symbol = sym.Name
+				if section := coffSectionOf(cf, sym.SectionNumber); section != nil {
+					reloc.symbolSection = section.Name
+				}
+			}
+			relocs = append(relocs, reloc)
+		}
+	}
+	return
+}
+
+func elfRelocs(ef *elf.File) (relocs []Reloc, err error) {
+	symbolTables := make(map[uint32][]elf.Symbol)
+
+	for _, s := range ef.Sections {
+		if s.Type != elf.SHT_REL && s.Type != elf.SHT_RELA {
+			continue
+		}
+		if int(s.Info) >= len(ef.Sections) || int(s.Link) >= len(ef.Sections) {
+			return nil, fmt.Errorf("section %s: invalid section link", s.Name)
+		}
+		target := ef.Sections[s.Info]
+
+		symbols, ok := symbolTables[s.Link]
+		if !ok {
+			if ef.Sections[s.Link].Type == elf.SHT_DYNSYM {
+				symbols, err = ef.DynamicSymbols()
+			} else {
+				symbols, err = ef.Symbols()
+			}
+			if err != nil && err != elf.ErrNoSymbols {
+				return nil, err
+			}
+			symbolTables[s.Link] = symbols
+		}
+
+		var data []byte
+		data, err = s.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		var entrySize int
+		switch {
+		case ef.Class == elf.ELFCLASS32 && s.Type == elf.SHT_REL:
+			entrySize = 8
+		case ef.Class == elf.ELFCLASS32 && s.Type == elf.SHT_RELA:
+			entrySize = 12
+		case ef.Class == elf.ELFCLASS64 && s.Type == elf.SHT_REL:
+			entrySize = 16
+		case ef.Class == elf.ELFCLASS64 && s.Type == elf.SHT_RELA:
+			entrySize = 24
+		default:
+			return nil, fmt.Errorf("unsupported ELF class %v", ef.Class)
+		}
+
+		for b := data; len(b) >= entrySize; b = b[entrySize:] {
+			var (
+				offset uint64
+				index  uint32
+				typ    uint32
+			)
+			if ef.Class == elf.ELFCLASS32 {
+				info := ef.ByteOrder.Uint32(b[4:])
+				offset = uint64(ef.ByteOrder.Uint32(b))
+				index = elf.R_SYM32(info)
+				typ = elf.R_TYPE32(info)
+			} else {
+				info := ef.ByteOrder.Uint64(b[8:])
+				offset = ef.ByteOrder.Uint64(b)
+				index = elf.R_SYM64(info)
+				typ = elf.R_TYPE64(info)
+			}
+
+			reloc := Reloc{
+				Section: target.Name,
+				Offset:  offset,
+				Type:    typ,
+			}
+
+			// Symbol index 0 is the undefined symbol, which Symbols omits
+			if index > 0 && int(index) <= len(symbols) {
+				sym := symbols[index-1]
+				reloc.Symbol = sym.Name
+				reloc.symbolSection = elfSectionName(ef, sym.Section)
+				if elf.ST_TYPE(sym.Info) == elf.STT_SECTION && int(sym.Section) < len(ef.Sections) {
+					reloc.Symbol = ef.Sections[sym.Section].Name
+				}
+			}
+
+			relocs = append(relocs, reloc)
+		}
+	}
+
+	return relocs, nil
+}
+
+// SymbolDependencyGraph returns the dependencies between the sections of a
+// relocatable file, as the input of dead code elimination. The nodes are the
+// names of the allocated sections and each edge is a pair of a section and a
+// section it refers to through a relocation. References from a section to
+// itself, to undefined symbols and from unallocated sections such as debug
+// information are left out. Each edge is listed once.
+func (f *File) SymbolDependencyGraph() (nodes []string, edges [][2]string, err error) {
+	relocs, err := f.Relocs()
+	if err != nil {
+		return
+	}
+
+	isNode := make(map[string]bool)
+	for _, s := range f.Sections {
+		if isAllocated(s) && !isNode[s.Name()] {
+			isNode[s.Name()] = true
+			nodes = append(nodes, s.Name())
+		}
+	}
+
+	seen := make(map[[2]string]bool)
+	for _, reloc := range relocs {
+		edge := [2]string{reloc.Section, reloc.symbolSection}
+		if edge[0] == edge[1] || !isNode[edge[0]] || !isNode[edge[1]] || seen[edge] {
+			continue
+		}
+		seen[edge] = true
+		edges = append(edges, edge)
+	}
+	return
+}
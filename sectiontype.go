@@ -0,0 +1,98 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"debug/elf"
+	"fmt"
+	"strings"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// A SectionType classifies the contents of a section.
+type SectionType int
+
+const (
+	SectionTypeUnknown SectionType = iota
+	SectionTypeText
+	SectionTypeData
+	SectionTypeROData
+	SectionTypeBSS
+	SectionTypeDebug
+	SectionTypeNote
+	SectionTypeComment
+	SectionTypeOther
+)
+
+func (t SectionType) String() string {
+	switch t {
+	case SectionTypeUnknown:
+		return "Unknown"
+	case SectionTypeText:
+		return "Text"
+	case SectionTypeData:
+		return "Data"
+	case SectionTypeROData:
+		return "ROData"
+	case SectionTypeBSS:
+		return "BSS"
+	case SectionTypeDebug:
+		return "Debug"
+	case SectionTypeNote:
+		return "Note"
+	case SectionTypeComment:
+		return "Comment"
+	case SectionTypeOther:
+		return "Other"
+	}
+	return fmt.Sprintf("SectionType%d", int(t))
+}
+
+// coffReadOnlySections are the names of the data sections the TI compilers
+// use for constant data.
+var coffReadOnlySections = []string{".const", ".econst", ".rodata"}
+
+func coffSectionType(s *coff.Section) SectionType {
+	switch {
+	case strings.HasPrefix(s.Name, ".debug"):
+		return SectionTypeDebug
+	case s.Name == ".comment":
+		return SectionTypeComment
+	case s.Flags&coff.STYP_TEXT != 0:
+		return SectionTypeText
+	case s.Flags&coff.STYP_BSS != 0:
+		return SectionTypeBSS
+	case s.Flags&coff.STYP_DATA != 0:
+		for _, name := range coffReadOnlySections {
+			if s.Name == name || strings.HasPrefix(s.Name, name+":") {
+				return SectionTypeROData
+			}
+		}
+		return SectionTypeData
+	}
+	return SectionTypeOther
+}
+
+func elfSectionType(s *elf.Section) SectionType {
+	switch {
+	case s.Type == elf.SHT_NULL:
+		return SectionTypeUnknown
+	case s.Type == elf.SHT_NOTE:
+		return SectionTypeNote
+	case s.Type == elf.SHT_NOBITS:
+		return SectionTypeBSS
+	case strings.HasPrefix(s.Name, ".debug") || strings.HasPrefix(s.Name, ".zdebug"):
+		return SectionTypeDebug
+	case s.Name == ".comment":
+		return SectionTypeComment
+	case s.Flags&elf.SHF_ALLOC == 0:
+		return SectionTypeOther
+	case s.Flags&elf.SHF_EXECINSTR != 0:
+		return SectionTypeText
+	case s.Flags&elf.SHF_WRITE != 0:
+		return SectionTypeData
+	}
+	return SectionTypeROData
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"debug/elf"
+)
+
+// A Segment is a loadable region of a file, as described by an ELF program
+// header.
+type Segment struct {
+	Type            elf.ProgType
+	Flags           elf.ProgFlag
+	Offset          uint64
+	VirtualAddress  uint64
+	PhysicalAddress uint64
+	FileSize        uint64
+	MemorySize      uint64
+	Align           uint64
+}
+
+// Segments returns the segments of the file. COFF files have no program
+// headers, so the result is always empty for them.
+func (f *File) Segments() []Segment {
+	if f.elf == nil {
+		return nil
+	}
+
+	segments := make([]Segment, len(f.elf.Progs))
+	for i, p := range f.elf.Progs {
+		segments[i] = Segment{
+			Type:            p.Type,
+			Flags:           p.Flags,
+			Offset:          p.Off,
+			VirtualAddress:  p.Vaddr,
+			PhysicalAddress: p.Paddr,
+			FileSize:        p.Filesz,
+			MemorySize:      p.Memsz,
+			Align:           p.Align,
+		}
+	}
+	return segments
+}
+
+// SegmentContaining returns the segment whose memory image contains addr. If
+// several segments overlap at addr, the one with the smallest memory size is
+// returned.
+func (f *File) SegmentContaining(addr uint64) (seg Segment, ok bool) {
+	for _, s := range f.Segments() {
+		if addr < s.VirtualAddress || addr-s.VirtualAddress >= s.MemorySize {
+			continue
+		}
+		if !ok || s.MemorySize < seg.MemorySize {
+			seg, ok = s, true
+		}
+	}
+	return
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+)
+
+// A SizeReport breaks down the size of a file's sections by section type.
+type SizeReport struct {
+	// TextBytes includes read-only data, as counted by the size utility.
+	TextBytes  uint64
+	DataBytes  uint64
+	BSSBytes   uint64
+	DebugBytes uint64
+	OtherBytes uint64
+
+	// SectionSizes maps section names to their size, sections sharing a name
+	// are summed.
+	SectionSizes map[string]uint64
+}
+
+// SizeBreakdown sums the section sizes by section type.
+func (f *File) SizeBreakdown() SizeReport {
+	report := SizeReport{
+		SectionSizes: make(map[string]uint64, len(f.Sections)),
+	}
+
+	for _, s := range f.Sections {
+		size := s.Size()
+		switch s.Type() {
+		case SectionTypeText, SectionTypeROData:
+			report.TextBytes += size
+		case SectionTypeData:
+			report.DataBytes += size
+		case SectionTypeBSS:
+			report.BSSBytes += size
+		case SectionTypeDebug:
+			report.DebugBytes += size
+		default:
+			report.OtherBytes += size
+		}
+		if s.Name() != "" {
+			report.SectionSizes[s.Name()] += size
+		}
+	}
+
+	return report
+}
+
+// Total returns the sum of all section sizes.
+func (r SizeReport) Total() uint64 {
+	return r.TextBytes + r.DataBytes + r.BSSBytes + r.DebugBytes + r.OtherBytes
+}
+
+func (r SizeReport) String() string {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "text\tdata\tbss\tdebug\tother\ttotal\t\n")
+	fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t\n", r.TextBytes, r.DataBytes, r.BSSBytes, r.DebugBytes, r.OtherBytes, r.Total())
+	w.Flush()
+
+	names := make([]string, 0, len(r.SectionSizes))
+	for name := range r.SectionSizes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf.WriteString("\n")
+	w = tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "section\tsize\n")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%d\n", name, r.SectionSizes[name])
+	}
+	w.Flush()
+
+	return buf.String()
+}
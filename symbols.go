@@ -0,0 +1,373 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+package debug
+
+import (
+	"bufio"
+	"debug/elf"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/awarepoint/go-debug/coff"
+)
+
+// A FunctionSymbol is a symbol identified as a function.
+type FunctionSymbol struct {
+	Name    string
+	Address uint64
+	Size    uint64
+	Section string
+}
+
+// Functions returns all symbols identified as functions, sorted by address.
+//
+// For ELF these are the STT_FUNC symbols. For COFF these are the C_FCN symbols
+// and the C_EXT symbols in text sections with a function size recorded in
+// their auxiliary entry.
+func (f *File) Functions() []FunctionSymbol {
+	var funcs []FunctionSymbol
+
+	switch {
+	case f.elf != nil:
+		symbols, _ := f.elf.Symbols()
+		for _, sym := range symbols {
+			if elf.ST_TYPE(sym.Info) != elf.STT_FUNC {
+				continue
+			}
+			funcs = append(funcs, FunctionSymbol{
+				Name:    sym.Name,
+				Address: sym.Value,
+				Size:    sym.Size,
+				Section: elfSectionName(f.elf, sym.Section),
+			})
+		}
+
+	case f.coff != nil:
+		symbols, _ := f.coff.Symbols()
+		for _, sym := range symbols {
+			section := coffSectionOf(f.coff, sym.SectionNumber)
+
+			var size uint64
+			if sym.AuxiliaryEntry != nil {
+				size = uint64(sym.AuxiliaryEntry.Size)
+			}
+
+			isText := section != nil && section.Flags&coff.STYP_TEXT != 0
+			if sym.StorageClass != coff.C_FCN && !(sym.StorageClass == coff.C_EXT && isText && size > 0) {
+				continue
+			}
+
+			fn := FunctionSymbol{
+				Name:    sym.Name,
+				Address: uint64(sym.Value),
+				Size:    size,
+			}
+			if section != nil {
+				fn.Section = section.Name
+			}
+			funcs = append(funcs, fn)
+		}
+	}
+
+	sort.SliceStable(funcs, func(i, j int) bool {
+		return funcs[i].Address < funcs[j].Address
+	})
+	return funcs
+}
+
+// SymbolCoverage returns the fraction of the text section bytes spanned by
+// function symbols with a size, as reported by Functions. Overlapping symbols
+// are counted once. It returns 0 if the file has no text sections.
+func (f *File) SymbolCoverage() float64 {
+	var text uint64
+	for _, s := range f.Sections {
+		if s.Type() == SectionTypeText {
+			text += s.Size()
+		}
+	}
+	if text == 0 {
+		return 0
+	}
+
+	var covered, end uint64
+	for _, fn := range f.Functions() {
+		if fn.Size == 0 {
+			continue
+		}
+		start := fn.Address
+		if start < end {
+			start = end
+		}
+		if fnEnd := fn.Address + fn.Size; fnEnd > start {
+			covered += fnEnd - start
+			end = fnEnd
+		}
+	}
+	return float64(covered) / float64(text)
+}
+
+// IsStripped reports whether the file has no symbols other than source file
+// names. ELF files without a .symtab section are always stripped.
+func (f *File) IsStripped() bool {
+	switch {
+	case f.elf != nil:
+		if f.elf.Section(".symtab") == nil {
+			return true
+		}
+		symbols, _ := f.elf.Symbols()
+		for _, sym := range symbols {
+			if elf.ST_TYPE(sym.Info) != elf.STT_FILE {
+				return false
+			}
+		}
+
+	case f.coff != nil:
+		symbols, _ := f.coff.Symbols()
+		for _, sym := range symbols {
+			if sym.StorageClass != coff.C_FILE {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SourceFiles returns the names of the source files recorded by the file
+// symbols (C_FILE for COFF, STT_FILE for ELF), without duplicates and in
+// symbol table order.
+func (f *File) SourceFiles() []string {
+	var names []string
+
+	switch {
+	case f.elf != nil:
+		symbols, _ := f.elf.Symbols()
+		for _, sym := range symbols {
+			if elf.ST_TYPE(sym.Info) == elf.STT_FILE {
+				names = append(names, sym.Name)
+			}
+		}
+
+	case f.coff != nil:
+		symbols, _ := f.coff.Symbols()
+		for _, sym := range symbols {
+			if sym.StorageClass == coff.C_FILE {
+				names = append(names, sym.Name)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	files := names[:0]
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// SymbolSections maps the name of each symbol to the name of the section it
+// is defined in, "ABS" for absolute symbols and "" for undefined symbols. If
+// several symbols share a name the last one in the symbol table wins.
+func (f *File) SymbolSections() map[string]string {
+	sections := make(map[string]string)
+
+	switch {
+	case f.elf != nil:
+		symbols, _ := f.elf.Symbols()
+		for _, sym := range symbols {
+			if sym.Name == "" {
+				continue
+			}
+			if sym.Section == elf.SHN_ABS {
+				sections[sym.Name] = "ABS"
+			} else {
+				sections[sym.Name] = elfSectionName(f.elf, sym.Section)
+			}
+		}
+
+	case f.coff != nil:
+		symbols, _ := f.coff.Symbols()
+		for _, sym := range symbols {
+			if sym.SectionNumber == -1 {
+				sections[sym.Name] = "ABS"
+			} else if section := coffSectionOf(f.coff, sym.SectionNumber); section != nil {
+				sections[sym.Name] = section.Name
+			} else {
+				sections[sym.Name] = ""
+			}
+		}
+	}
+	return sections
+}
+
+// elfSectionName returns the name of the section with the given index, or ""
+// for the reserved indexes.
+func elfSectionName(ef *elf.File, index elf.SectionIndex) string {
+	if index == elf.SHN_UNDEF || index >= elf.SHN_LORESERVE || int(index) >= len(ef.Sections) {
+		return ""
+	}
+	return ef.Sections[index].Name
+}
+
+// coffSectionOf returns the section a symbol's section number refers to, or
+// nil for undefined, absolute and debugging symbols.
+func coffSectionOf(cf *coff.File, sectionNumber int16) *coff.Section {
+	if sectionNumber < 1 || int(sectionNumber) > len(cf.Sections) {
+		return nil
+	}
+	return cf.Sections[sectionNumber-1]
+}
+
+// nmSymbol is a symbol table entry as listed by ObjdumpSymbols.
+type nmSymbol struct {
+	name    string
+	address uint64
+	size    uint64
+	kind    byte
+}
+
+// ObjdumpSymbols writes the symbol table to w in the format of nm -S, sorted by
+// address: address, size, type character and name. Upper case types are
+// global and lower case local: T (text), D (data), B (bss), R (read-only
+// data), A (absolute), U (undefined) and W (weak). Debugging, file and
+// section symbols are not listed.
+func (f *File) ObjdumpSymbols(w io.Writer) error {
+	var (
+		symbols []nmSymbol
+		width   = 8
+	)
+
+	switch {
+	case f.elf != nil:
+		if f.elf.Class == elf.ELFCLASS64 {
+			width = 16
+		}
+		elfSymbols, err := f.elf.Symbols()
+		if err != nil && err != elf.ErrNoSymbols {
+			return err
+		}
+		for _, sym := range elfSymbols {
+			typ := elf.ST_TYPE(sym.Info)
+			if typ == elf.STT_FILE || typ == elf.STT_SECTION {
+				continue
+			}
+			symbols = append(symbols, nmSymbol{
+				name:    sym.Name,
+				address: sym.Value,
+				size:    sym.Size,
+				kind:    elfSymbolKind(f.elf, sym),
+			})
+		}
+
+	case f.coff != nil:
+		coffSymbols, err := f.coff.Symbols()
+		if err != nil {
+			return err
+		}
+		for _, sym := range coffSymbols {
+			if sym.SectionNumber == -2 || sym.StorageClass == coff.C_FILE {
+				continue
+			}
+			// Section symbols are named after their section
+			if section := coffSectionOf(f.coff, sym.SectionNumber); section != nil && sym.Name == section.Name {
+				continue
+			}
+			nsym := nmSymbol{
+				name:    sym.Name,
+				address: uint64(sym.Value),
+				kind:    coffSymbolKind(f.coff, sym),
+			}
+			if sym.AuxiliaryEntry != nil {
+				nsym.size = uint64(sym.AuxiliaryEntry.Size)
+			}
+			symbols = append(symbols, nsym)
+		}
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].address < symbols[j].address
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, sym := range symbols {
+		if sym.kind == 'U' || sym.kind == 'w' {
+			fmt.Fprintf(bw, "%*s %c %s\n", 2*width+1, "", sym.kind, sym.name)
+		} else {
+			fmt.Fprintf(bw, "%0*x %0*x %c %s\n", width, sym.address, width, sym.size, sym.kind, sym.name)
+		}
+	}
+	return bw.Flush()
+}
+
+// elfSymbolKind returns the nm type character of an ELF symbol.
+func elfSymbolKind(ef *elf.File, sym elf.Symbol) byte {
+	bind := elf.ST_BIND(sym.Info)
+
+	var kind byte
+	switch {
+	case sym.Section == elf.SHN_UNDEF:
+		if bind == elf.STB_WEAK {
+			return 'w'
+		}
+		return 'U'
+	case bind == elf.STB_WEAK:
+		return 'W'
+	case sym.Section == elf.SHN_ABS:
+		kind = 'A'
+	case sym.Section >= elf.SHN_LORESERVE || int(sym.Section) >= len(ef.Sections):
+		kind = 'D'
+	default:
+		s := ef.Sections[sym.Section]
+		switch {
+		case s.Flags&elf.SHF_EXECINSTR != 0:
+			kind = 'T'
+		case s.Type == elf.SHT_NOBITS:
+			kind = 'B'
+		case s.Flags&elf.SHF_WRITE != 0:
+			kind = 'D'
+		default:
+			kind = 'R'
+		}
+	}
+
+	if bind == elf.STB_LOCAL {
+		kind += 'a' - 'A'
+	}
+	return kind
+}
+
+// coffSymbolKind returns the nm type character of a COFF symbol.
+func coffSymbolKind(cf *coff.File, sym coff.Symbol) byte {
+	var kind byte
+	switch sym.SectionNumber {
+	case 0:
+		return 'U'
+	case -1:
+		kind = 'A'
+	default:
+		section := coffSectionOf(cf, sym.SectionNumber)
+		switch {
+		case section == nil:
+			kind = 'D'
+		case section.Flags&coff.STYP_TEXT != 0:
+			kind = 'T'
+		case section.Flags&coff.STYP_BSS != 0:
+			kind = 'B'
+		case section.Flags&coff.STYP_DATA != 0:
+			kind = 'D'
+		default:
+			kind = 'R'
+		}
+	}
+
+	switch sym.StorageClass {
+	case coff.C_EXT, coff.C_EXTLAB:
+	default:
+		kind += 'a' - 'A'
+	}
+	return kind
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2015-2018 Awarepoint Corporation. All rights reserved.
+// AWAREPOINT PROPRIETARY/CONFIDENTIAL. Use is subject to license terms.
+
+// libversioned.so is a stripped shared library with a versioned dynamic
+// symbol, built with:
+//
+//	gcc -shared -fPIC -Os -nostdlib -Wl,--version-script=libversioned.map \
+//		-Wl,-z,noseparate-code -Wl,-z,max-page-size=0x10 \
+//		-Wl,-z,common-page-size=0x10 -Wl,--build-id=none \
+//		-o libversioned.so libversioned.c
+//	strip -R .comment libversioned.so
+
+int answer(void) { return 42; }